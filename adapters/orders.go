@@ -10,6 +10,7 @@ import (
 	mo "github.com/mdhender/wraithh/models/orders"
 	"github.com/mdhender/wraithh/models/units"
 	po "github.com/mdhender/wraithh/parsers/orders"
+	"strings"
 )
 
 func CoordToEngineCoord(in po.Coordinates) coordinates.Coordinates {
@@ -17,7 +18,7 @@ func CoordToEngineCoord(in po.Coordinates) coordinates.Coordinates {
 		X:      in.X,
 		Y:      in.Y,
 		Z:      in.Z,
-		System: in.System,
+		System: strings.ToUpper(in.System), // the parser returns lower case suffixes
 		Orbit:  in.Orbit,
 	}
 }
@@ -33,14 +34,31 @@ func ItemsToEngineItems(in []*po.TransferDetail) (out []*mo.TransferDetail) {
 }
 
 func UnitToEngineUnit(in po.Unit) units.Unit {
+	// the parser names tech levels "TL-n", but the engine keeps the level
+	// separate so that the unit prints and saves the same way as any other.
+	if strings.HasPrefix(in.Name, "TL-") {
+		return units.Unit{Name: "TL", TechLevel: in.TechLevel}
+	}
 	return units.Unit{
 		Name:      in.Name,
 		TechLevel: in.TechLevel,
 	}
 }
 
-func OrdersToEngineOrders(in []any) (out []mo.Order) {
+// OrdersToEngineOrders converts parsed orders to engine orders.
+// Orders that failed to parse are converted to rejected orders that
+// carry the line from the source so that the player can see what was wrong.
+func OrdersToEngineOrders(in []any, source []byte) (out []mo.Order) {
+	lines := strings.Split(string(source), "\n")
 	for _, o := range in {
+		if line, errs := parseErrors(o); errs != nil {
+			rejected := &ec.Rejected{Line: line, Errors: errs}
+			if 0 < line && line <= len(lines) {
+				rejected.Source = strings.TrimSpace(lines[line-1])
+			}
+			out = append(out, rejected)
+			continue
+		}
 		switch order := o.(type) {
 		case *po.Abandon:
 			out = append(out, &ec.Abandon{
@@ -392,11 +410,118 @@ func OrdersToEngineOrders(in []any) (out []mo.Order) {
 				Unit:     UnitToEngineUnit(order.Unit),
 				TargetId: order.TargetId,
 			})
-		case *po.Unknown:
-			// ignore unknown orders
 		default:
 			panic(fmt.Sprintf("unknown type %T", o))
 		}
 	}
 	return out
 }
+
+// parseErrors returns the line number and any errors from a parsed order.
+func parseErrors(o any) (int, []error) {
+	switch order := o.(type) {
+	case *po.Abandon:
+		return order.Line, order.Errors
+	case *po.AssembleFactoryGroup:
+		return order.Line, order.Errors
+	case *po.AssembleMineGroup:
+		return order.Line, order.Errors
+	case *po.AssembleUnit:
+		return order.Line, order.Errors
+	case *po.Bombard:
+		return order.Line, order.Errors
+	case *po.Buy:
+		return order.Line, order.Errors
+	case *po.CheckRebels:
+		return order.Line, order.Errors
+	case *po.Claim:
+		return order.Line, order.Errors
+	case *po.ConvertRebels:
+		return order.Line, order.Errors
+	case *po.CounterAgents:
+		return order.Line, order.Errors
+	case *po.Discharge:
+		return order.Line, order.Errors
+	case *po.Draft:
+		return order.Line, order.Errors
+	case *po.ExpandFactoryGroup:
+		return order.Line, order.Errors
+	case *po.ExpandMineGroup:
+		return order.Line, order.Errors
+	case *po.Grant:
+		return order.Line, order.Errors
+	case *po.InciteRebels:
+		return order.Line, order.Errors
+	case *po.Invade:
+		return order.Line, order.Errors
+	case *po.Jump:
+		return order.Line, order.Errors
+	case *po.Move:
+		return order.Line, order.Errors
+	case *po.Name:
+		return order.Line, order.Errors
+	case *po.NameUnit:
+		return order.Line, order.Errors
+	case *po.News:
+		return order.Line, order.Errors
+	case *po.PayAll:
+		return order.Line, order.Errors
+	case *po.PayLocal:
+		return order.Line, order.Errors
+	case *po.Probe:
+		return order.Line, order.Errors
+	case *po.ProbeSystem:
+		return order.Line, order.Errors
+	case *po.Raid:
+		return order.Line, order.Errors
+	case *po.RationAll:
+		return order.Line, order.Errors
+	case *po.RationLocal:
+		return order.Line, order.Errors
+	case *po.RecycleFactoryGroup:
+		return order.Line, order.Errors
+	case *po.RecycleMineGroup:
+		return order.Line, order.Errors
+	case *po.RecycleUnit:
+		return order.Line, order.Errors
+	case *po.RetoolFactoryGroup:
+		return order.Line, order.Errors
+	case *po.Revoke:
+		return order.Line, order.Errors
+	case *po.ScrapFactoryGroup:
+		return order.Line, order.Errors
+	case *po.ScrapMineGroup:
+		return order.Line, order.Errors
+	case *po.ScrapUnit:
+		return order.Line, order.Errors
+	case *po.Secret:
+		return order.Line, order.Errors
+	case *po.Sell:
+		return order.Line, order.Errors
+	case *po.Setup:
+		return order.Line, order.Errors
+	case *po.StealSecrets:
+		return order.Line, order.Errors
+	case *po.StoreFactoryGroup:
+		return order.Line, order.Errors
+	case *po.StoreMineGroup:
+		return order.Line, order.Errors
+	case *po.StoreUnit:
+		return order.Line, order.Errors
+	case *po.SupportAttack:
+		return order.Line, order.Errors
+	case *po.SupportDefend:
+		return order.Line, order.Errors
+	case *po.SuppressAgents:
+		return order.Line, order.Errors
+	case *po.Survey:
+		return order.Line, order.Errors
+	case *po.SurveySystem:
+		return order.Line, order.Errors
+	case *po.Transfer:
+		return order.Line, order.Errors
+	case *po.Unknown:
+		return order.Line, order.Errors
+	}
+	return 0, []error{fmt.Errorf("unknown type %T", o)}
+}
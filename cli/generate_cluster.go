@@ -9,6 +9,7 @@ import (
 	"github.com/spf13/cobra"
 	"log"
 	"os"
+	"path/filepath"
 )
 
 // cmdGenerateCluster runs the cluster generator command
@@ -27,7 +28,12 @@ var cmdGenerateCluster = &cobra.Command{
 		} else {
 			optCluster = append(optCluster, opt)
 		}
-		if opt, err := clusters.SetSystems(128); err != nil {
+		if opt, err := clusters.SetTemplate(argsGenerateCluster.template); err != nil {
+			log.Fatal(err)
+		} else {
+			optCluster = append(optCluster, opt)
+		}
+		if opt, err := clusters.SetSystems(argsGenerateCluster.systems); err != nil {
 			log.Fatal(err)
 		} else {
 			optCluster = append(optCluster, opt)
@@ -37,41 +43,53 @@ var cmdGenerateCluster = &cobra.Command{
 		} else {
 			optCluster = append(optCluster, opt)
 		}
+		if cmd.Flags().Changed("seed") {
+			if opt, err := clusters.SetSeed(argsGenerateCluster.seed); err != nil {
+				log.Fatal(err)
+			} else {
+				optCluster = append(optCluster, opt)
+			}
+		}
 
 		c, sy, st, err := clusters.Generate(optCluster...)
 		if err != nil {
 			log.Fatal(err)
 		}
+		outDir := filepath.Clean(argsGenerateCluster.outDir)
 		// adapt c to json
 		if data, err := json.MarshalIndent(c, "", "  "); err != nil {
 			log.Fatal(err)
-		} else if err = os.WriteFile("g1/out/cluster.json", data, 0660); err != nil {
+		} else if err = os.WriteFile(filepath.Join(outDir, "cluster.json"), data, 0660); err != nil {
 			log.Fatal(err)
 		}
-		log.Printf("cluster: created g1/out/cluster.json")
+		log.Printf("cluster: created %s\n", filepath.Join(outDir, "cluster.json"))
 		// adapt sy to json
 		if data, err := json.MarshalIndent(sy, "", "  "); err != nil {
 			log.Fatal(err)
-		} else if err = os.WriteFile("g1/out/systems.json", data, 0660); err != nil {
+		} else if err = os.WriteFile(filepath.Join(outDir, "systems.json"), data, 0660); err != nil {
 			log.Fatal(err)
 		}
-		log.Printf("cluster: created g1/out/stars.json")
+		log.Printf("cluster: created %s\n", filepath.Join(outDir, "systems.json"))
 		// adapt st to json
 		if data, err := json.MarshalIndent(st, "", "  "); err != nil {
 			log.Fatal(err)
-		} else if err = os.WriteFile("g1/out/stars.json", data, 0660); err != nil {
+		} else if err = os.WriteFile(filepath.Join(outDir, "stars.json"), data, 0660); err != nil {
 			log.Fatal(err)
 		}
-		log.Printf("cluster: created g1/out/stars.json")
+		log.Printf("cluster: created %s\n", filepath.Join(outDir, "stars.json"))
 
 		return nil
 	},
 }
 
 var argsGenerateCluster struct {
-	kind    string // uniform, cluster, surface
-	mapFile string
-	radius  float64
+	kind     string // uniform, cluster, surface
+	mapFile  string
+	outDir   string
+	radius   float64
+	seed     int64
+	systems  int
+	template string
 }
 
 func init() {
@@ -81,6 +99,10 @@ func init() {
 	cmdGenerateCluster.Flags().StringVar(&argsGenerateCluster.kind, "kind", "uniform", "point distribution (uniform, clustered, sphere)")
 	cmdGenerateCluster.Flags().StringVar(&argsGenerateCluster.mapFile, "html-map", "", "name of map file to create (optional)")
 	cmdGenerateCluster.Flags().Float64Var(&argsGenerateCluster.radius, "radius", 15.0, "cluster radius")
+	cmdGenerateCluster.Flags().Int64Var(&argsGenerateCluster.seed, "seed", 0, "seed for the random number generator (optional)")
+	cmdGenerateCluster.Flags().IntVar(&argsGenerateCluster.systems, "systems", 128, "number of systems to seed the cluster with")
+	cmdGenerateCluster.Flags().StringVar(&argsGenerateCluster.template, "template", "", "path to the map template (optional)")
 
 	// outputs
+	cmdGenerateCluster.Flags().StringVar(&argsGenerateCluster.outDir, "out-dir", ".", "directory to write the cluster files to")
 }
@@ -0,0 +1,122 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package cli
+
+import (
+	"fmt"
+	"github.com/mdhender/wraithh/adapters"
+	"github.com/mdhender/wraithh/ec"
+	"github.com/mdhender/wraithh/parsers/orders"
+	"github.com/mdhender/wraithh/reports"
+	"github.com/spf13/cobra"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// cmdProcess runs the process command
+var cmdProcess = &cobra.Command{
+	Use:   "process game-directory",
+	Short: "process a turn",
+	Long:  `Load a game, apply every player's orders, and save the results.`,
+	Args:  cobra.ExactArgs(1),
+	// errors in orders are not usage errors
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := filepath.Clean(args[0])
+
+		e, err := ec.LoadGame(path)
+		if err != nil {
+			return err
+		}
+
+		// failures holds the errors for each player, keyed by handle
+		// (or by file name when we can't tell who sent the orders).
+		failures := make(map[string][]error)
+
+		// discover every player's order file
+		files, err := filepath.Glob(filepath.Join(path, "orders", "*.txt"))
+		if err != nil {
+			return err
+		}
+		sort.Strings(files)
+		log.Printf("process: found %d order files\n", len(files))
+
+		for _, name := range files {
+			key := filepath.Base(name)
+			input, err := os.ReadFile(name)
+			if err != nil {
+				failures[key] = append(failures[key], err)
+				continue
+			}
+			lexemes, err := orders.Scan(input)
+			if err != nil {
+				failures[key] = append(failures[key], err)
+				continue
+			}
+			ods := orders.Parse(lexemes)
+			if argsProcess.debug {
+				for _, od := range ods {
+					fmt.Println(od)
+				}
+			}
+			if err = e.AddOrders(adapters.OrdersToEngineOrders(ods, input)); err != nil {
+				failures[key] = append(failures[key], err)
+			}
+		}
+
+		if err = e.Process(); err != nil {
+			return err
+		}
+		for _, po := range e.Orders {
+			if !po.Validated {
+				failures[po.Handle] = append(failures[po.Handle], po.Error)
+			}
+			if argsProcess.debug {
+				for _, r := range po.Results {
+					fmt.Printf("%-20s %-12s %4d %v\n", po.Handle, r.Phase, r.Line, r.Error)
+				}
+			}
+		}
+
+		if err = e.SaveGame(path); err != nil {
+			return err
+		}
+		if err = reports.Save(e, path, argsProcess.reportTemplate); err != nil {
+			return err
+		}
+
+		if len(failures) != 0 {
+			var keys []string
+			for key := range failures {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			sb := &strings.Builder{}
+			for _, key := range keys {
+				for _, err := range failures[key] {
+					sb.WriteString(fmt.Sprintf("%-20s %v\n", key, err))
+				}
+			}
+			_, _ = fmt.Fprint(os.Stderr, sb.String())
+			return fmt.Errorf("process: %d players had errors", len(failures))
+		}
+
+		return nil
+	},
+}
+
+var argsProcess struct {
+	debug          bool
+	reportTemplate string
+}
+
+func init() {
+	cmdRoot.AddCommand(cmdProcess)
+
+	cmdProcess.Flags().BoolVar(&argsProcess.debug, "debug", false, "print parsed orders and their results")
+	cmdProcess.Flags().StringVar(&argsProcess.reportTemplate, "report-template", "", "path to the HTML report template (optional)")
+}
@@ -0,0 +1,441 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package ec
+
+import (
+	"fmt"
+	"github.com/mdhender/wraithh/models/coordinates"
+	"github.com/mdhender/wraithh/models/orders"
+	"github.com/mdhender/wraithh/models/ships"
+	"github.com/mdhender/wraithh/models/units"
+	"math/rand"
+	"sort"
+	"strconv"
+)
+
+const (
+	// combatRounds is the most rounds a battle lasts.
+	combatRounds = 5
+	// raidRounds is the most rounds a battle lasts when every attacker is raiding.
+	raidRounds = 2
+	// bombardDamage is the largest fraction of a target's installations
+	// that a single bombardment can destroy.
+	bombardDamage = 0.25
+)
+
+// firepower is the strength of a single unit in combat, per tech level.
+// Soldiers fight with assault weapons when they have them.
+var firepower = map[string]float64{
+	"SLD":  1,
+	"ASWP": 1, // only when carried by a soldier
+	"ASCR": 5,
+	"EWPN": 20,
+	"MILR": 10,
+	"MSLN": 15,
+}
+
+// shielding is the defensive strength of a single unit, per tech level.
+var shielding = map[string]float64{
+	"AMSL": 5,
+	"ESHD": 50,
+}
+
+// Battle is the outcome of a fight over a single target.
+type Battle struct {
+	Turn          int
+	Location      coordinates.Coordinates
+	TargetId      string
+	Attackers     []string // ids of units on the attacking side
+	Defenders     []string // ids of units on the defending side
+	Nations       []string // ids of every nation involved, sorted
+	Rounds        int
+	AttackerWon   bool
+	AttackersLost int      // soldiers killed on the attacking side
+	DefendersLost int      // soldiers killed on the defending side
+	Raiders       []string // ids of units raiding the target
+	Plunder       []*Plunder
+	Events        []string // what happened, in order
+}
+
+// combatant is a unit taking part in a battle.
+type combatant struct {
+	handle    string
+	nation    string
+	order     orders.Order
+	unit      *ships.Ship
+	pct       int // percentage of the unit's forces committed
+	committed int // soldiers committed at the start of the battle
+	soldiers  int // committed soldiers still alive
+}
+
+// engagement holds every combatant fighting over a single target.
+type engagement struct {
+	target    *ships.Ship
+	attackers []*combatant // bombard, invade and raid orders, and their supporters
+	defenders []*combatant // the target and the units supporting it
+}
+
+// combatPhase gathers every player's combat orders, groups them by the
+// unit being attacked and then fights each battle.
+//
+// Battles are fought in order of location and then target so that the
+// results only depend on the game's seed and the orders.
+func combatPhase(e *Engine, orders []*Orders) error {
+	engagements := make(map[string]*engagement)
+	engage := func(target *ships.Ship) *engagement {
+		eg, ok := engagements[target.Id]
+		if !ok {
+			eg = &engagement{target: target}
+			eg.defenders = append(eg.defenders, &combatant{nation: target.Owner, unit: target, pct: 100})
+			engagements[target.Id] = eg
+		}
+		return eg
+	}
+
+	// attacks are gathered first so that supporters have a battle to join
+	for _, po := range orders {
+		for _, order := range po.Orders {
+			var line int
+			var err error
+			switch o := order.(type) {
+			case *Bombard:
+				line, err = o.Line, e.attack(po, o, o.Id, o.PctCommitted, o.TargetId, engage)
+			case *Invade:
+				line, err = o.Line, e.invade(po, o, engage)
+			case *Raid:
+				line, err = o.Line, e.raid(po, o, engage)
+			default:
+				continue
+			}
+			e.result(po, line, order, err)
+		}
+	}
+	for _, po := range orders {
+		for _, order := range po.Orders {
+			var line int
+			var err error
+			switch o := order.(type) {
+			case *SupportAttack:
+				line, err = o.Line, e.supportAttack(po, o, engagements)
+			case *SupportDefend:
+				line, err = o.Line, e.supportDefend(po, o, engagements)
+			default:
+				continue
+			}
+			e.result(po, line, order, err)
+		}
+	}
+
+	var list []*engagement
+	for _, eg := range engagements {
+		if len(eg.attackers) != 0 {
+			list = append(list, eg)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i].target, list[j].target
+		if a.Location.String() != b.Location.String() {
+			return a.Location.String() < b.Location.String()
+		}
+		return UnitIdLess(a.Id, b.Id)
+	})
+	r := rand.New(rand.NewSource(e.Game.Seed + int64(e.Game.Turn)))
+	for _, eg := range list {
+		e.Battles = append(e.Battles, e.fight(r, eg))
+	}
+	return nil
+}
+
+// attack validates a bombard or raid order and adds it to the battle
+// for the target.
+func (e *Engine) attack(po *Orders, order orders.Order, id, pct, targetId int, engage func(*ships.Ship) *engagement) error {
+	unit, err := e.ownedUnit(po.Nation, id)
+	if err != nil {
+		return err
+	} else if !(0 < pct && pct <= 100) {
+		return fmt.Errorf("percentage committed must be between 1%% and 100%%")
+	}
+	target, ok := e.unit(targetId)
+	if !ok || !sameOrbit(unit.Location, target.Location) {
+		return fmt.Errorf("unit %d is not in orbit with unit %d", id, targetId)
+	} else if target.Owner == po.Nation {
+		return fmt.Errorf("unit %d is one of your own", targetId)
+	}
+	eg := engage(target)
+	eg.attackers = append(eg.attackers, &combatant{handle: po.Handle, nation: po.Nation, order: order, unit: unit, pct: pct})
+	return nil
+}
+
+func (e *Engine) invade(po *Orders, o *Invade, engage func(*ships.Ship) *engagement) error {
+	if target, ok := e.unit(o.TargetId); ok && target.Kind == ships.Vessel {
+		return fmt.Errorf("unit %d is a ship and can't be invaded", o.TargetId)
+	}
+	return e.attack(po, o, o.Id, o.PctCommitted, o.TargetId, engage)
+}
+
+func (e *Engine) supportAttack(po *Orders, o *SupportAttack, engagements map[string]*engagement) error {
+	unit, err := e.ownedUnit(po.Nation, o.Id)
+	if err != nil {
+		return err
+	} else if !(0 < o.PctCommitted && o.PctCommitted <= 100) {
+		return fmt.Errorf("percentage committed must be between 1%% and 100%%")
+	}
+	eg, ok := engagements[strconv.Itoa(o.TargetId)]
+	if !ok {
+		return fmt.Errorf("unit %d is not being attacked", o.TargetId)
+	} else if !sameOrbit(unit.Location, eg.target.Location) {
+		return fmt.Errorf("unit %d is not in orbit with unit %d", o.Id, o.TargetId)
+	}
+	var supported bool
+	for _, c := range eg.attackers {
+		supported = supported || c.unit.Id == strconv.Itoa(o.SupportId)
+	}
+	if !supported {
+		return fmt.Errorf("unit %d is not attacking unit %d", o.SupportId, o.TargetId)
+	}
+	eg.attackers = append(eg.attackers, &combatant{handle: po.Handle, nation: po.Nation, order: o, unit: unit, pct: o.PctCommitted})
+	return nil
+}
+
+func (e *Engine) supportDefend(po *Orders, o *SupportDefend, engagements map[string]*engagement) error {
+	unit, err := e.ownedUnit(po.Nation, o.Id)
+	if err != nil {
+		return err
+	} else if !(0 < o.PctCommitted && o.PctCommitted <= 100) {
+		return fmt.Errorf("percentage committed must be between 1%% and 100%%")
+	}
+	eg, ok := engagements[strconv.Itoa(o.SupportId)]
+	if !ok {
+		// nobody attacked the unit, so there is nothing to do
+		return nil
+	} else if !sameOrbit(unit.Location, eg.target.Location) {
+		return fmt.Errorf("unit %d is not in orbit with unit %d", o.Id, o.SupportId)
+	}
+	eg.defenders = append(eg.defenders, &combatant{handle: po.Handle, nation: po.Nation, order: o, unit: unit, pct: o.PctCommitted})
+	return nil
+}
+
+// fight resolves a single battle.
+//
+// Each round both sides fire at the same time. The hits a side scores
+// are a random share of its strength, and every hit kills one of the
+// committed soldiers on the other side. The battle ends early when a
+// side has no strength left. The attackers win if they end the battle
+// stronger than the defenders.
+func (e *Engine) fight(r *rand.Rand, eg *engagement) *Battle {
+	b := &Battle{Turn: e.Game.Turn, Location: eg.target.Location, TargetId: eg.target.Id}
+	nations := make(map[string]bool)
+	for _, c := range eg.attackers {
+		b.Attackers = appendOnce(b.Attackers, c.unit.Id)
+		if _, ok := c.order.(*Raid); ok {
+			b.Raiders = appendOnce(b.Raiders, c.unit.Id)
+		}
+		nations[c.nation] = true
+	}
+	for _, c := range eg.defenders {
+		b.Defenders = appendOnce(b.Defenders, c.unit.Id)
+		nations[c.nation] = true
+	}
+	for nation := range nations {
+		b.Nations = append(b.Nations, nation)
+	}
+	sort.Strings(b.Nations)
+
+	for _, c := range append(append([]*combatant{}, eg.attackers...), eg.defenders...) {
+		c.committed = c.unit.Population["SLD"] * c.pct / 100
+		c.soldiers = c.committed
+	}
+
+	rounds := combatRounds
+	if eg.raidOnly() {
+		rounds = raidRounds
+	}
+	var attack, defend float64
+	for round := 1; round <= rounds; round++ {
+		attack, defend = e.strength(eg.attackers, false), e.strength(eg.defenders, true)
+		if attack == 0 || defend == 0 {
+			break
+		}
+		b.Rounds = round
+		attackHits := int(attack * (0.05 + 0.10*r.Float64()))
+		defendHits := int(defend * (0.05 + 0.10*r.Float64()))
+		lostA := casualties(eg.attackers, defendHits)
+		lostD := casualties(eg.defenders, attackHits)
+		b.AttackersLost, b.DefendersLost = b.AttackersLost+lostA, b.DefendersLost+lostD
+		b.Events = append(b.Events, fmt.Sprintf("round %d: attackers %.0f lost %d soldiers, defenders %.0f lost %d soldiers", round, attack, lostA, defend, lostD))
+	}
+	attack, defend = e.strength(eg.attackers, false), e.strength(eg.defenders, true)
+	b.AttackerWon = attack > defend
+	if !b.AttackerWon {
+		b.Events = append(b.Events, "the defenders held")
+		return b
+	}
+	b.Events = append(b.Events, "the attackers won")
+
+	for _, c := range eg.attackers {
+		switch o := c.order.(type) {
+		case *Bombard:
+			b.Events = append(b.Events, bombard(r, eg.target, e.strength([]*combatant{c}, false)/attack))
+		case *Raid:
+			if p := e.plunder(c, eg.target, o.TargetUnit, e.strength([]*combatant{c}, false)/attack); p != nil {
+				b.Plunder = append(b.Plunder, p)
+				b.Events = append(b.Events, fmt.Sprintf("unit %s took %d %s from unit %s", p.RaiderId, p.Quantity, p.Unit, eg.target.Id))
+			}
+		}
+	}
+	for _, c := range eg.attackers {
+		if _, ok := c.order.(*Invade); ok && eg.target.Owner != c.nation {
+			if eg.target.Population["SLD"] > 0 {
+				b.Events = append(b.Events, fmt.Sprintf("unit %s still has soldiers and was not captured", eg.target.Id))
+				break
+			}
+			b.Events = append(b.Events, fmt.Sprintf("unit %s captured colony %s", c.unit.Id, eg.target.Id))
+			eg.target.Owner = c.nation
+			eg.target.Pay, eg.target.Rations, eg.target.Morale = nil, 0, 0
+			break
+		}
+	}
+	return b
+}
+
+// strength returns the combined strength of the combatants.
+// Defenders add their shields to their strength. Weapons need soldiers
+// to use them, so a combatant with no committed soldiers left adds nothing.
+func (e *Engine) strength(list []*combatant, defending bool) float64 {
+	var total float64
+	for _, c := range list {
+		sld := c.soldiers
+		if sld == 0 {
+			// no soldiers were committed, or they are all dead
+			continue
+		}
+		var power float64
+		for unit, qty := range arms(c.unit) {
+			if unit.Name == "ASWP" && qty > sld {
+				qty = sld
+			}
+			power += float64(qty) * firepower[unit.Name] * float64(techLevelOf(unit))
+			if defending {
+				power += float64(qty) * shielding[unit.Name] * float64(techLevelOf(unit))
+			}
+		}
+		power += float64(sld) * firepower["SLD"]
+		// weapons are committed in the same share as the soldiers
+		total += power * float64(c.pct) / 100 * (1 + float64(e.techLevel(c.nation))/10)
+	}
+	return total
+}
+
+// arms returns the weapons and shields on the unit, installed or stored.
+func arms(s *ships.Ship) map[units.Unit]int {
+	list := make(map[units.Unit]int)
+	for _, m := range []map[units.Unit]int{s.Installed, s.Stored} {
+		for unit, qty := range m {
+			if firepower[unit.Name] != 0 || shielding[unit.Name] != 0 {
+				list[unit] += qty
+			}
+		}
+	}
+	return list
+}
+
+// casualties kills soldiers on one side, spread across the combatants
+// in proportion to the soldiers they committed. It returns the number killed.
+func casualties(list []*combatant, hits int) int {
+	var total int
+	for _, c := range list {
+		total += c.soldiers
+	}
+	if total == 0 || hits <= 0 {
+		return 0
+	}
+	if hits > total {
+		hits = total
+	}
+	var killed int
+	for _, c := range list {
+		dead := hits * c.soldiers / total
+		if dead > c.soldiers {
+			dead = c.soldiers
+		}
+		c.soldiers -= dead
+		c.unit.AddPopulation("SLD", -dead)
+		killed += dead
+	}
+	return killed
+}
+
+// bombard destroys a share of the target's installations. Hulls are
+// not damaged. The share grows with the bombarding unit's part in the attack.
+func bombard(r *rand.Rand, target *ships.Ship, share float64) string {
+	fraction := bombardDamage * share * (0.5 + 0.5*r.Float64())
+	var destroyed int
+	for _, unit := range sortedUnits(target.Installed) {
+		if unit.IsStructural() {
+			continue
+		}
+		n := int(float64(target.Installed[unit]) * fraction)
+		target.AddInstalled(unit, -n)
+		destroyed += n
+	}
+	for _, fg := range target.FactoryGroups {
+		for _, unit := range sortedUnits(fg.Factories) {
+			if n := int(float64(fg.Factories[unit]) * fraction); n > 0 {
+				_ = takeFromGroup(fg.Factories, unit, n)
+				destroyed += n
+			}
+		}
+	}
+	for _, mg := range target.MineGroups {
+		for _, unit := range sortedUnits(mg.Mines) {
+			if n := int(float64(mg.Mines[unit]) * fraction); n > 0 {
+				_ = takeFromGroup(mg.Mines, unit, n)
+				destroyed += n
+			}
+		}
+	}
+	return fmt.Sprintf("bombardment destroyed %d installations on unit %s", destroyed, target.Id)
+}
+
+// techLevelOf returns the tech level of a unit, treating zero as one.
+func techLevelOf(unit units.Unit) int {
+	if unit.TechLevel < 1 {
+		return 1
+	}
+	return unit.TechLevel
+}
+
+// sortedUnits returns the units in the map sorted by name and tech level.
+func sortedUnits(m map[units.Unit]int) []units.Unit {
+	var list []units.Unit
+	for unit := range m {
+		list = append(list, unit)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Name != list[j].Name {
+			return list[i].Name < list[j].Name
+		}
+		return list[i].TechLevel < list[j].TechLevel
+	})
+	return list
+}
+
+// UnitIdLess compares unit ids numerically, so that unit 2 sorts before unit 10.
+func UnitIdLess(a, b string) bool {
+	na, _ := strconv.Atoi(a)
+	nb, _ := strconv.Atoi(b)
+	if na != nb {
+		return na < nb
+	}
+	return a < b
+}
+
+func appendOnce(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package ec
+
+import (
+	"github.com/mdhender/wraithh/models/coordinates"
+	"github.com/mdhender/wraithh/models/orders"
+	"github.com/mdhender/wraithh/models/player"
+	"github.com/mdhender/wraithh/models/units"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// testProcess adds a player for every nation in the engine and runs a
+// full turn with each nation's orders. The player for nation "1" has the
+// handle "nation-1" and the secret "secret-1".
+func testProcess(t *testing.T, e *Engine, list map[string][]orders.Order) {
+	t.Helper()
+	e.Game.Id, e.Game.Turn = "G1", 1
+	e.Players = make(map[string]player.Player)
+	e.Phases = DefaultPhases()
+	var ids []string
+	for id := range e.Nations {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		secret, err := player.HashSecret("secret-" + id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.Players[id] = player.Player{Id: id, Handle: "nation-" + id, Secret: secret, Nation: id}
+		set := []orders.Order{&Secret{Line: 1, Handle: "nation-" + id, Game: "G1", Turn: 1, Token: "secret-" + id}}
+		if err := e.AddOrders(append(set, list[id]...)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := e.Process(); err != nil {
+		t.Fatalf("process: %v", err)
+	}
+	for _, po := range e.Orders {
+		if po.Error != nil {
+			t.Fatalf("%s: %v", po.Handle, po.Error)
+		}
+	}
+}
+
+// testBattle returns an engine where nation 1's ship 10 and nation 2's
+// ship 20 are in the same orbit, both armed and crewed.
+func testBattle(seed int64) *Engine {
+	e := testEngine("1", "2")
+	e.Game.Seed = seed
+	orbit := coordinates.Coordinates{System: "A", Orbit: 4}
+	testShip(e, 10, "1", orbit, 500).AddInstalled(units.Unit{Name: "MSLN", TechLevel: 1}, 20)
+	testShip(e, 20, "2", orbit, 400).AddInstalled(units.Unit{Name: "AMSL", TechLevel: 1}, 20)
+	return e
+}
+
+func TestCombatIsReproducible(t *testing.T) {
+	list := map[string][]orders.Order{
+		"1": {&Bombard{Line: 2, Id: 10, PctCommitted: 100, TargetId: 20}},
+	}
+	first, second := testBattle(42), testBattle(42)
+	testProcess(t, first, list)
+	testProcess(t, second, list)
+
+	if len(first.Battles) != 1 {
+		t.Fatalf("battles: got %d, want 1", len(first.Battles))
+	} else if b := first.Battles[0]; b.Rounds == 0 || b.AttackersLost == 0 || b.DefendersLost == 0 {
+		t.Fatalf("battle: got %+v, want losses on both sides", b)
+	}
+	if !reflect.DeepEqual(first.Battles, second.Battles) {
+		t.Errorf("battles differ for the same seed:\n%+v\n%+v", first.Battles[0], second.Battles[0])
+	}
+	if !reflect.DeepEqual(first.Ships, second.Ships) {
+		t.Errorf("ships differ for the same seed")
+	}
+}
+
+func TestStrengthNeedsSoldiers(t *testing.T) {
+	e := testBattle(42)
+	msln := units.Unit{Name: "MSLN", TechLevel: 1}
+	for _, tc := range []struct {
+		name     string
+		soldiers int
+		want     bool // true if the unit has strength
+	}{
+		{name: "soldiers", soldiers: 10, want: true},
+		{name: "no soldiers", soldiers: 0, want: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := e.Ships["10"]
+			s.Population["SLD"] = tc.soldiers
+			c := &combatant{nation: "1", unit: s, pct: 100}
+			c.committed, c.soldiers = tc.soldiers, tc.soldiers
+			if got := e.strength([]*combatant{c}, false); (got > 0) != tc.want {
+				t.Errorf("strength: got %g with %d %s", got, s.Installed[msln], msln)
+			}
+		})
+	}
+}
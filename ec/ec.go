@@ -5,8 +5,17 @@
 package ec
 
 import (
+	"fmt"
+	"github.com/mdhender/wraithh/models/cluster"
+	"github.com/mdhender/wraithh/models/coordinates"
 	"github.com/mdhender/wraithh/models/games"
+	"github.com/mdhender/wraithh/models/knowledge"
+	"github.com/mdhender/wraithh/models/nations"
+	"github.com/mdhender/wraithh/models/orbits"
 	"github.com/mdhender/wraithh/models/player"
+	"github.com/mdhender/wraithh/models/ships"
+	"github.com/mdhender/wraithh/models/systems"
+	"strconv"
 )
 
 // Engine holds the state of a single game
@@ -17,6 +26,123 @@ type Engine struct {
 	// Players holds every player that has ever been in this game.
 	Players map[string]player.Player
 
+	// Nations holds every nation in the game, keyed by id.
+	Nations map[string]*nations.Nation
+
+	// Knowledge holds what each nation has learned about the cluster,
+	// keyed by nation id.
+	Knowledge map[string]*knowledge.Knowledge
+
+	// Cluster holds the dimensions of the cluster.
+	Cluster *cluster.Cluster
+
+	// Systems holds every system in the cluster, keyed by id.
+	Systems map[string]*systems.System
+
+	// Stars holds every star in the cluster, keyed by id.
+	Stars map[string]*systems.Star
+
+	// Orbits holds every orbit in the cluster, keyed by id.
+	// The orbits are owned by the stars; this is an index into them.
+	Orbits map[string]*orbits.Orbit
+
+	// Deposits holds every deposit in the cluster, keyed by id.
+	// The deposits are owned by the orbits; this is an index into them.
+	Deposits map[string]*orbits.Deposit
+
+	// Ships holds every ship in the game, keyed by id.
+	Ships map[string]*ships.Ship
+
+	// Colonies holds every colony in the game, keyed by id.
+	Colonies map[string]*ships.Ship
+
+	// News holds the articles published this turn.
+	News []*Article
+
+	// Battles holds the battles fought this turn.
+	Battles []*Battle
+
+	// Missions holds the spy missions run this turn.
+	Missions []*Mission
+
+	// Revolts holds the revolts on colonies this turn.
+	Revolts []*Revolt
+
+	// Permits holds the rights to use orbits granted or revoked this turn.
+	Permits []*Permit
+
+	// Market holds the clearing prices for this turn.
+	// Every player can see them.
+	Market []*Price
+
 	// Orders holds every player's set of orders for the current turn.
 	Orders []*Orders
+
+	// Processed is the turn that Process ran. By the time the game is
+	// saved and the reports are written, Game.Turn is the next turn.
+	Processed int
+
+	// Phases holds the steps of processing a turn, in the order they run.
+	Phases []Phase
+
+	// phase is the name of the phase currently running.
+	phase string
+}
+
+// unit returns the ship or colony with the given id.
+func (e *Engine) unit(id int) (*ships.Ship, bool) {
+	if s, ok := e.Ships[strconv.Itoa(id)]; ok {
+		return s, true
+	}
+	s, ok := e.Colonies[strconv.Itoa(id)]
+	return s, ok
+}
+
+// ownedUnit returns the ship or colony with the given id if the nation owns it.
+// Units owned by other nations are reported as not existing so that orders
+// can't be used to discover them.
+func (e *Engine) ownedUnit(nation string, id int) (*ships.Ship, error) {
+	s, ok := e.unit(id)
+	if !ok || s.Owner != nation {
+		return nil, fmt.Errorf("unit %d does not exist", id)
+	}
+	return s, nil
+}
+
+// indexOrbits rebuilds the orbit and deposit indexes from the stars.
+func (e *Engine) indexOrbits() {
+	e.Orbits = make(map[string]*orbits.Orbit)
+	e.Deposits = make(map[string]*orbits.Deposit)
+	for _, star := range e.Stars {
+		for o := 1; o < len(star.Orbits); o++ {
+			orbit := &star.Orbits[o]
+			e.Orbits[orbit.Id] = orbit
+			for d := range orbit.Deposits {
+				e.Deposits[orbit.Deposits[d].Id] = &orbit.Deposits[d]
+			}
+		}
+	}
+}
+
+// orbitOf returns the orbit that holds the deposit.
+func (e *Engine) orbitOf(depositId string) *orbits.Orbit {
+	for _, orbit := range e.Orbits {
+		for _, deposit := range orbit.Deposits {
+			if deposit.Id == depositId {
+				return orbit
+			}
+		}
+	}
+	return nil
+}
+
+// orbitAt returns the orbit at the location.
+func (e *Engine) orbitAt(location coordinates.Coordinates) (*orbits.Orbit, error) {
+	star, ok := e.Stars[location.StarLocation().String()]
+	if !ok {
+		return nil, fmt.Errorf("star %s does not exist", location.StarLocation())
+	} else if !(0 < location.Orbit && location.Orbit < len(star.Orbits)) {
+		return nil, fmt.Errorf("orbit %d does not exist", location.Orbit)
+	}
+	return &star.Orbits[location.Orbit], nil
 }
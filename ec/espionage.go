@@ -0,0 +1,242 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package ec
+
+import (
+	"fmt"
+	"github.com/mdhender/wraithh/models/ships"
+	"math/rand"
+	"strconv"
+)
+
+const (
+	// rebelsPerSpy is the number of rebels that a single spy can
+	// convert back to loyal workers, or incite from them, in a turn.
+	rebelsPerSpy = 10
+	// idleCounterSpies is the share of a colony's spies that guard it
+	// when they have not been given a mission.
+	idleCounterSpies = 0.1
+	// suppressFactor is how much more effective a spy hunting a single
+	// nation's agents is than a spy guarding against everyone.
+	suppressFactor = 2
+)
+
+// Mission is the outcome of a single spy mission.
+type Mission struct {
+	Kind      string // check-rebels, convert-rebels, counter-agents, suppress-agents, incite-rebels or steal-secrets
+	Nation    string // nation running the mission
+	Target    string // nation being spied on, empty for missions at home
+	UnitId    string // unit the spies came from
+	ColonyId  string // unit where the mission took place
+	Spies     int    // spies committed to the mission
+	Succeeded bool
+	Caught    int    // spies caught and lost
+	Result    string // what the mission found or did
+}
+
+// espionage holds the spies committed during the phase.
+type espionage struct {
+	committed map[*ships.Ship]int            // spies on each unit that have a mission
+	counter   map[*ships.Ship]int            // spies guarding each unit against everyone
+	suppress  map[*ships.Ship]map[string]int // spies on each unit hunting a single nation's agents
+}
+
+// espionagePhase resolves every player's spy missions.
+//
+// Defensive missions are gathered first so that every colony's guards
+// are in place before any enemy agents arrive. Missions against another
+// nation pit the spies against the counter-spies at the target colony;
+// spies that fail are caught and lost.
+func espionagePhase(e *Engine, orders []*Orders) error {
+	es := &espionage{
+		committed: make(map[*ships.Ship]int),
+		counter:   make(map[*ships.Ship]int),
+		suppress:  make(map[*ships.Ship]map[string]int),
+	}
+	for _, po := range orders {
+		for _, order := range po.Orders {
+			var line int
+			var err error
+			switch o := order.(type) {
+			case *CounterAgents:
+				line, err = o.Line, e.counterAgents(es, po.Nation, o)
+			case *SuppressAgents:
+				line, err = o.Line, e.suppressAgents(es, po.Nation, o)
+			default:
+				continue
+			}
+			e.result(po, line, order, err)
+		}
+	}
+
+	r := rand.New(rand.NewSource(e.Game.Seed + int64(e.Game.Turn)))
+	for _, po := range orders {
+		for _, order := range po.Orders {
+			var line int
+			var err error
+			switch o := order.(type) {
+			case *CheckRebels:
+				line, err = o.Line, e.checkRebels(es, po.Nation, o)
+			case *ConvertRebels:
+				line, err = o.Line, e.convertRebels(es, po.Nation, o)
+			case *InciteRebels:
+				line, err = o.Line, e.spyOn(r, es, po.Nation, "incite-rebels", o.Id, o.Quantity, o.TargetId, inciteRebels)
+			case *StealSecrets:
+				line, err = o.Line, e.spyOn(r, es, po.Nation, "steal-secrets", o.Id, o.Quantity, o.TargetId, e.stealSecrets)
+			default:
+				continue
+			}
+			e.result(po, line, order, err)
+		}
+	}
+	return nil
+}
+
+// commit sets aside spies on the unit for a mission.
+func (es *espionage) commit(s *ships.Ship, qty int) error {
+	if qty < 1 {
+		return fmt.Errorf("quantity must be positive")
+	} else if free := s.Population["SPY"] - es.committed[s]; free < qty {
+		return fmt.Errorf("unit %s has %d SPY without a mission, needs %d", s.Id, free, qty)
+	}
+	es.committed[s] += qty
+	return nil
+}
+
+func (e *Engine) counterAgents(es *espionage, nation string, o *CounterAgents) error {
+	s, err := e.ownedUnit(nation, o.Id)
+	if err != nil {
+		return err
+	} else if err = es.commit(s, o.Quantity); err != nil {
+		return err
+	}
+	es.counter[s] += o.Quantity
+	e.Missions = append(e.Missions, &Mission{
+		Kind: "counter-agents", Nation: nation, UnitId: s.Id, ColonyId: s.Id, Spies: o.Quantity, Succeeded: true,
+		Result: fmt.Sprintf("%d spies are guarding unit %s", o.Quantity, s.Id),
+	})
+	return nil
+}
+
+func (e *Engine) suppressAgents(es *espionage, nation string, o *SuppressAgents) error {
+	s, err := e.ownedUnit(nation, o.Id)
+	if err != nil {
+		return err
+	}
+	target := strconv.Itoa(o.TargetId)
+	if _, ok := e.Nations[target]; !ok || target == nation {
+		return fmt.Errorf("nation %d does not exist", o.TargetId)
+	} else if err = es.commit(s, o.Quantity); err != nil {
+		return err
+	}
+	if es.suppress[s] == nil {
+		es.suppress[s] = make(map[string]int)
+	}
+	es.suppress[s][target] += o.Quantity
+	e.Missions = append(e.Missions, &Mission{
+		Kind: "suppress-agents", Nation: nation, Target: target, UnitId: s.Id, ColonyId: s.Id, Spies: o.Quantity, Succeeded: true,
+		Result: fmt.Sprintf("%d spies are hunting agents of nation %s on unit %s", o.Quantity, target, s.Id),
+	})
+	return nil
+}
+
+func (e *Engine) checkRebels(es *espionage, nation string, o *CheckRebels) error {
+	s, err := e.ownedUnit(nation, o.Id)
+	if err != nil {
+		return err
+	} else if err = es.commit(s, o.Quantity); err != nil {
+		return err
+	}
+	e.Missions = append(e.Missions, &Mission{
+		Kind: "check-rebels", Nation: nation, UnitId: s.Id, ColonyId: s.Id, Spies: o.Quantity, Succeeded: true,
+		Result: fmt.Sprintf("unit %s has %d rebels", s.Id, s.Rebels),
+	})
+	return nil
+}
+
+// convertRebels talks rebels into returning to work as unskilled workers.
+func (e *Engine) convertRebels(es *espionage, nation string, o *ConvertRebels) error {
+	s, err := e.ownedUnit(nation, o.Id)
+	if err != nil {
+		return err
+	} else if err = es.commit(s, o.Quantity); err != nil {
+		return err
+	}
+	converted := o.Quantity * rebelsPerSpy
+	if converted > s.Rebels {
+		converted = s.Rebels
+	}
+	s.Rebels -= converted
+	s.AddPopulation("UNSK", converted)
+	e.Missions = append(e.Missions, &Mission{
+		Kind: "convert-rebels", Nation: nation, UnitId: s.Id, ColonyId: s.Id, Spies: o.Quantity, Succeeded: true,
+		Result: fmt.Sprintf("converted %d rebels on unit %s, %d remain", converted, s.Id, s.Rebels),
+	})
+	return nil
+}
+
+// spyOn sends spies against a colony owned by the target nation in the
+// same orbit as the unit. If there are several, the lowest numbered
+// colony is chosen.
+//
+// The chance of success is the spies' strength divided by the combined
+// strength of the spies and the counter-spies at the colony. Spies that
+// succeed run the mission and return home; spies that fail are caught.
+func (e *Engine) spyOn(r *rand.Rand, es *espionage, nation, kind string, id, qty, targetId int, mission func(m *Mission, spies int, colony *ships.Ship) string) error {
+	s, err := e.ownedUnit(nation, id)
+	if err != nil {
+		return err
+	}
+	target := strconv.Itoa(targetId)
+	if _, ok := e.Nations[target]; !ok || target == nation {
+		return fmt.Errorf("nation %d does not exist", targetId)
+	}
+	var colony *ships.Ship
+	for _, c := range sortShips(e.Colonies) {
+		if c.Owner == target && sameOrbit(c.Location, s.Location) {
+			colony = c
+			break
+		}
+	}
+	if colony == nil {
+		return fmt.Errorf("nation %d has no colony in orbit with unit %d", targetId, id)
+	} else if err = es.commit(s, qty); err != nil {
+		return err
+	}
+
+	m := &Mission{Kind: kind, Nation: nation, Target: target, UnitId: s.Id, ColonyId: colony.Id, Spies: qty}
+	attack := float64(qty) * (1 + float64(e.techLevel(nation))/10)
+	defend := e.counterSpies(es, colony, nation)
+	m.Succeeded = defend == 0 || r.Float64() < attack/(attack+defend)
+	if m.Succeeded {
+		m.Result = mission(m, qty, colony)
+	} else {
+		m.Caught = qty
+		s.AddPopulation("SPY", -qty)
+		es.committed[s] -= qty
+		m.Result = fmt.Sprintf("%d spies were caught on unit %s", qty, colony.Id)
+	}
+	e.Missions = append(e.Missions, m)
+	return nil
+}
+
+// counterSpies returns the strength of the counter-spies guarding the
+// colony against the nation's agents.
+func (e *Engine) counterSpies(es *espionage, colony *ships.Ship, nation string) float64 {
+	idle := colony.Population["SPY"] - es.committed[colony]
+	guards := float64(es.counter[colony]) + suppressFactor*float64(es.suppress[colony][nation]) + idleCounterSpies*float64(idle)
+	return guards * (1 + float64(e.techLevel(colony.Owner))/10)
+}
+
+// inciteRebels turns unskilled workers, then civilians, into rebels.
+func inciteRebels(m *Mission, spies int, colony *ships.Ship) string {
+	incited := recruitRebels(colony, spies*rebelsPerSpy)
+	return fmt.Sprintf("incited %d rebels on unit %s", incited, colony.Id)
+}
+
+// stealSecrets hands the nation a copy of the target nation's report
+// from the previous turn. The copy is made when the reports are saved.
+func (e *Engine) stealSecrets(m *Mission, spies int, colony *ships.Ship) string {
+	return fmt.Sprintf("stole the turn %d report of nation %s", e.Game.Turn-1, m.Target)
+}
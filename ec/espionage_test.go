@@ -0,0 +1,155 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package ec
+
+import (
+	"github.com/mdhender/wraithh/models/coordinates"
+	"github.com/mdhender/wraithh/models/orders"
+	"github.com/mdhender/wraithh/models/ships"
+	"testing"
+)
+
+// testSpies returns an engine where nation 1's ship 10 carries spies
+// and is in orbit with nation 2's colony 20, which has its own spies.
+func testSpies(seed int64, spies, guards int) *Engine {
+	e := testEngine("1", "2", "3")
+	e.Game.Seed, e.Game.Turn = seed, 5
+	orbit := coordinates.Coordinates{System: "A", Orbit: 3}
+	testShip(e, 10, "1", orbit, 0).Population["SPY"] = spies
+	colony := testShip(e, 20, "2", orbit, 0)
+	colony.Kind = ships.OpenColony
+	colony.Population["SPY"] = guards
+	delete(e.Ships, colony.Id)
+	e.Colonies[colony.Id] = colony
+	return e
+}
+
+func TestCounterAgentsStopMissions(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		guards int          // spies on the colony
+		orders orders.Order // nation 2's order for the colony's spies
+		caught bool
+	}{
+		{name: "unguarded", guards: 0, caught: false},
+		{name: "counter-agents", guards: 10_000, orders: &CounterAgents{Line: 1, Id: 20, Quantity: 10_000}, caught: true},
+		{name: "suppress-agents", guards: 10_000, orders: &SuppressAgents{Line: 1, Id: 20, Quantity: 10_000, TargetId: 1}, caught: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			e := testSpies(42, 1, tc.guards)
+			// the thief's orders run first, so the guards must be posted
+			// before any mission is resolved for them to matter
+			thief := &Orders{Validated: true, Handle: "alice", Nation: "1", Orders: []orders.Order{
+				&StealSecrets{Line: 1, Id: 10, Quantity: 1, TargetId: 2},
+			}}
+			guard := &Orders{Validated: true, Handle: "bob", Nation: "2"}
+			if tc.orders != nil {
+				guard.Orders = []orders.Order{tc.orders}
+			}
+
+			if err := espionagePhase(e, []*Orders{thief, guard}); err != nil {
+				t.Fatalf("espionage: %v", err)
+			}
+			for _, po := range []*Orders{thief, guard} {
+				for _, r := range po.Results {
+					if r.Error != nil {
+						t.Fatalf("%s: line %d: %v", po.Handle, r.Line, r.Error)
+					}
+				}
+			}
+			var m *Mission
+			for _, mission := range e.Missions {
+				if mission.Kind == "steal-secrets" {
+					m = mission
+				}
+			}
+			if m == nil {
+				t.Fatalf("missions: no steal-secrets mission in %+v", e.Missions)
+			} else if caught := m.Caught != 0; caught != tc.caught || m.Succeeded == tc.caught {
+				t.Fatalf("mission: got %+v, want caught %v", m, tc.caught)
+			}
+			want := 1
+			if tc.caught {
+				want = 0
+			}
+			if spies := e.Ships["10"].Population["SPY"]; spies != want {
+				t.Errorf("spies: got %d, want %d", spies, want)
+			}
+		})
+	}
+}
+
+func TestCounterSpies(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		idle     int            // spies on the colony without a mission
+		counter  int            // spies guarding against everyone
+		suppress map[string]int // spies hunting each nation's agents
+		want     float64        // strength against nation 1's agents
+	}{
+		{name: "idle spies", idle: 100, want: 11},
+		{name: "counter-agents", counter: 100, want: 110},
+		{name: "suppressing the spies' nation", suppress: map[string]int{"1": 100}, want: 220},
+		{name: "suppressing another nation", suppress: map[string]int{"3": 100}, want: 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			e := testSpies(42, 0, 0)
+			colony := e.Colonies["20"]
+			es := &espionage{
+				committed: map[*ships.Ship]int{colony: tc.counter},
+				counter:   map[*ships.Ship]int{colony: tc.counter},
+				suppress:  map[*ships.Ship]map[string]int{colony: tc.suppress},
+			}
+			for _, qty := range tc.suppress {
+				es.committed[colony] += qty
+			}
+			colony.Population["SPY"] = tc.idle + es.committed[colony]
+
+			if got := e.counterSpies(es, colony, "1"); got < tc.want-0.001 || got > tc.want+0.001 {
+				t.Errorf("strength: got %g, want %g", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSpyDetection(t *testing.T) {
+	// with equal strength on both sides, about half the missions succeed
+	var succeeded int
+	for seed := int64(1); seed <= 1000; seed++ {
+		e := testSpies(seed, 10, 10)
+		po := &Orders{Validated: true, Handle: "alice", Nation: "1", Orders: []orders.Order{
+			&InciteRebels{Line: 1, Id: 10, Quantity: 10, TargetId: 2},
+		}}
+		guard := &Orders{Validated: true, Handle: "bob", Nation: "2", Orders: []orders.Order{
+			&CounterAgents{Line: 1, Id: 20, Quantity: 10},
+		}}
+		if err := espionagePhase(e, []*Orders{po, guard}); err != nil {
+			t.Fatalf("espionage: %v", err)
+		}
+		if m := e.Missions[len(e.Missions)-1]; m.Succeeded {
+			succeeded++
+		}
+	}
+	if succeeded < 450 || succeeded > 550 {
+		t.Errorf("succeeded: got %d of 1000, want about 500", succeeded)
+	}
+
+	// the same seed always gives the same outcome
+	for seed := int64(1); seed <= 10; seed++ {
+		var outcomes []bool
+		for i := 0; i < 2; i++ {
+			e := testSpies(seed, 10, 10)
+			po := &Orders{Validated: true, Handle: "alice", Nation: "1", Orders: []orders.Order{
+				&StealSecrets{Line: 1, Id: 10, Quantity: 10, TargetId: 2},
+			}}
+			if err := espionagePhase(e, []*Orders{po}); err != nil {
+				t.Fatalf("espionage: %v", err)
+			}
+			outcomes = append(outcomes, e.Missions[0].Succeeded)
+		}
+		if outcomes[0] != outcomes[1] {
+			t.Errorf("seed %d: outcomes differ", seed)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package ec
+
+import (
+	"fmt"
+	"github.com/mdhender/wraithh/models/ships"
+	"github.com/mdhender/wraithh/models/units"
+)
+
+// recycleShare is the fraction of a unit's mass recovered by recycling it.
+// Half of what is recovered is metallics and half is non-metallics.
+const recycleShare = 0.5
+
+// disposal is what happens to units once they have been removed.
+type disposal func(s *ships.Ship, unit units.Unit, qty int)
+
+// recycle breaks the units down into metallics and non-metallics.
+func recycle(s *ships.Ship, unit units.Unit, qty int) {
+	recovered := int(float64(qty*unit.Mass()) * recycleShare)
+	s.AddResource("MTL", recovered/2)
+	s.AddResource("NMTL", recovered-recovered/2)
+}
+
+// scrap destroys the units.
+func scrap(s *ships.Ship, unit units.Unit, qty int) {}
+
+// store puts the units in the warehouse.
+func store(s *ships.Ship, unit units.Unit, qty int) {
+	s.AddStored(unit, qty)
+}
+
+// removeUnit recycles or scraps units. Units in storage are used first,
+// then installed units.
+func (e *Engine) removeUnit(nation string, id int, unit units.Unit, qty int, dispose disposal) error {
+	s, err := e.ownedUnit(nation, id)
+	if err != nil {
+		return err
+	} else if qty < 1 {
+		return fmt.Errorf("quantity must be positive")
+	} else if !unit.IsProduct() {
+		return fmt.Errorf("%s is not a product", unit)
+	} else if have := s.Stored[unit] + s.Installed[unit]; have < qty {
+		return fmt.Errorf("unit %d has %d %s, needs %d", id, have, unit, qty)
+	}
+	stored := qty
+	if stored > s.Stored[unit] {
+		stored = s.Stored[unit]
+	}
+	if err := uninstall(s, unit, qty-stored); err != nil {
+		return err
+	}
+	s.AddStored(unit, -stored)
+	dispose(s, unit, qty)
+	return nil
+}
+
+// assembleUnit installs units from storage, making them operational.
+// The nation must be able to build the units. Factories and mines are
+// put to work by assembling them into groups instead.
+func (e *Engine) assembleUnit(nation string, o *AssembleUnit) error {
+	s, err := e.ownedUnit(nation, o.Id)
+	if err != nil {
+		return err
+	} else if o.Quantity < 1 {
+		return fmt.Errorf("quantity must be positive")
+	} else if !installs(o.Unit) {
+		return fmt.Errorf("%s can't be installed", o.Unit)
+	} else if err = e.canBuild(nation, o.Unit); err != nil {
+		return err
+	} else if have := s.Stored[o.Unit]; have < o.Quantity {
+		return fmt.Errorf("unit %d has %d %s in storage, needs %d", o.Id, have, o.Unit, o.Quantity)
+	}
+	s.AddStored(o.Unit, -o.Quantity)
+	s.AddInstalled(o.Unit, o.Quantity)
+	return nil
+}
+
+// storeUnit moves installed units into storage.
+func (e *Engine) storeUnit(nation string, o *StoreUnit) error {
+	s, err := e.ownedUnit(nation, o.Id)
+	if err != nil {
+		return err
+	} else if o.Quantity < 1 {
+		return fmt.Errorf("quantity must be positive")
+	} else if have := s.Installed[o.Unit]; have < o.Quantity {
+		return fmt.Errorf("unit %d has %d %s installed, needs %d", o.Id, have, o.Unit, o.Quantity)
+	}
+	if err := uninstall(s, o.Unit, o.Quantity); err != nil {
+		return err
+	}
+	s.AddStored(o.Unit, o.Quantity)
+	// stored hull still takes up space inside the rest of the hull
+	if s.Volume() > s.Capacity() {
+		s.AddStored(o.Unit, -o.Quantity)
+		s.AddInstalled(o.Unit, o.Quantity)
+		return fmt.Errorf("unit %d needs the cargo space provided by %d %s", o.Id, o.Quantity, o.Unit)
+	}
+	return nil
+}
+
+// uninstall removes installed units. It fails if removing structural
+// units would leave the hull too small for the cargo.
+func uninstall(s *ships.Ship, unit units.Unit, qty int) error {
+	if qty == 0 {
+		return nil
+	}
+	s.AddInstalled(unit, -qty)
+	if s.Volume() > s.Capacity() {
+		s.AddInstalled(unit, qty)
+		return fmt.Errorf("unit %s needs the cargo space provided by %d %s", s.Id, qty, unit)
+	}
+	return nil
+}
+
+// removeFactories takes factories out of a group. The group is
+// dismantled when the last factory is removed.
+func (e *Engine) removeFactories(nation string, id int, group string, unit units.Unit, qty int, dispose disposal) error {
+	s, err := e.ownedUnit(nation, id)
+	if err != nil {
+		return err
+	}
+	fg := factoryGroup(s, group)
+	if fg == nil {
+		return fmt.Errorf("factory group %s does not exist", group)
+	} else if err = takeFromGroup(fg.Factories, unit, qty); err != nil {
+		return fmt.Errorf("factory group %s: %w", group, err)
+	}
+	if len(fg.Factories) == 0 {
+		for i := range s.FactoryGroups {
+			if s.FactoryGroups[i] == fg {
+				s.FactoryGroups = append(s.FactoryGroups[:i], s.FactoryGroups[i+1:]...)
+				break
+			}
+		}
+	}
+	dispose(s, unit, qty)
+	return nil
+}
+
+// removeMines takes mines out of a group. The group is dismantled
+// when the last mine is removed.
+func (e *Engine) removeMines(nation string, id int, group string, unit units.Unit, qty int, dispose disposal) error {
+	s, err := e.ownedUnit(nation, id)
+	if err != nil {
+		return err
+	}
+	mg := mineGroup(s, group)
+	if mg == nil {
+		return fmt.Errorf("mine group %s does not exist", group)
+	} else if err = takeFromGroup(mg.Mines, unit, qty); err != nil {
+		return fmt.Errorf("mine group %s: %w", group, err)
+	}
+	if len(mg.Mines) == 0 {
+		for i := range s.MineGroups {
+			if s.MineGroups[i] == mg {
+				s.MineGroups = append(s.MineGroups[:i], s.MineGroups[i+1:]...)
+				break
+			}
+		}
+	}
+	dispose(s, unit, qty)
+	return nil
+}
+
+// takeFromGroup removes units from a factory or mine group.
+func takeFromGroup(group map[units.Unit]int, unit units.Unit, qty int) error {
+	if qty < 1 {
+		return fmt.Errorf("quantity must be positive")
+	} else if group[unit] < qty {
+		return fmt.Errorf("has %d %s, needs %d", group[unit], unit, qty)
+	}
+	group[unit] -= qty
+	if group[unit] == 0 {
+		delete(group, unit)
+	}
+	return nil
+}
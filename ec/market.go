@@ -0,0 +1,299 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package ec
+
+import (
+	"fmt"
+	"github.com/mdhender/wraithh/models/ships"
+	"github.com/mdhender/wraithh/models/units"
+	"math"
+	"sort"
+)
+
+// Price is the outcome of the market for a single unit in a turn.
+type Price struct {
+	Turn    int
+	Unit    units.Unit
+	Bids    int     // number of units bid for
+	Asks    int     // number of units offered
+	Traded  int     // number of units that changed hands
+	Price   float64 // clearing price per unit, zero if nothing traded
+	HighBid float64
+	LowAsk  float64
+}
+
+// offer is a validated Buy or Sell waiting for the market to clear.
+// The goods (for a sell) or gold (for a buy) are held by the market
+// until it clears, and anything left over is returned.
+type offer struct {
+	handle string
+	nation string
+	line   int
+	unit   *ships.Ship
+	qty    int // units still wanted or offered
+	price  float64
+	held   int // gold held for a buy
+}
+
+// marketPhase collects every player's Buy and Sell orders and clears
+// the market for each unit in a sealed-bid auction.
+//
+// Bids are filled highest first and asks lowest first. Ties are broken
+// by player handle and then by line number so that the results are
+// repeatable. A nation's bids are never matched with its own asks.
+// Every trade for a unit happens at a single clearing price, halfway
+// between the lowest bid and the highest ask that were matched.
+func marketPhase(e *Engine, orders []*Orders) error {
+	bids := make(map[units.Unit][]*offer)
+	asks := make(map[units.Unit][]*offer)
+	// space holds the cargo space promised to buyers, keyed by ship id
+	space := make(map[string]int)
+
+	for _, po := range orders {
+		for _, order := range po.Orders {
+			switch o := order.(type) {
+			case *Buy:
+				of, err := e.bid(po, o, space)
+				if err == nil {
+					bids[o.Unit] = append(bids[o.Unit], of)
+				}
+				e.result(po, o.Line, o, err)
+			case *Sell:
+				of, err := e.ask(po, o)
+				if err == nil {
+					asks[o.Unit] = append(asks[o.Unit], of)
+				}
+				e.result(po, o.Line, o, err)
+			}
+		}
+	}
+
+	// clear each unit in a consistent order
+	seen := make(map[units.Unit]bool)
+	var list []units.Unit
+	for _, m := range []map[units.Unit][]*offer{bids, asks} {
+		for unit := range m {
+			if !seen[unit] {
+				seen[unit] = true
+				list = append(list, unit)
+			}
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Name != list[j].Name {
+			return list[i].Name < list[j].Name
+		}
+		return list[i].TechLevel < list[j].TechLevel
+	})
+	for _, unit := range list {
+		e.Market = append(e.Market, e.clear(unit, bids[unit], asks[unit]))
+	}
+	return nil
+}
+
+// bid validates a Buy order and holds the buyer's gold.
+func (e *Engine) bid(po *Orders, o *Buy, space map[string]int) (*offer, error) {
+	ship, err := e.ownedUnit(po.Nation, o.Id)
+	if err != nil {
+		return nil, err
+	} else if err = e.canTrade(po.Nation, ship); err != nil {
+		return nil, err
+	} else if o.Quantity < 1 {
+		return nil, fmt.Errorf("quantity must be positive")
+	} else if !(o.Bid > 0) {
+		return nil, fmt.Errorf("bid must be positive")
+	} else if o.Unit.Name == "GOLD" {
+		return nil, fmt.Errorf("gold can't be bought")
+	} else if o.Unit.Name == "TL" {
+		if o.Quantity != 1 {
+			return nil, fmt.Errorf("%s can only be bought once", o.Unit)
+		} else if tl := e.techLevel(po.Nation); o.Unit.TechLevel != tl+1 {
+			return nil, fmt.Errorf("nation is at tech level %d and can't buy %s", tl, o.Unit)
+		}
+	} else if o.Unit.Mass() == 0 && !isKnowledge(o.Unit) {
+		return nil, fmt.Errorf("%s can't be bought", o.Unit)
+	}
+	gold := int(math.Ceil(float64(o.Quantity) * o.Bid))
+	// the gold leaves the ship, freeing space for the goods
+	need := o.Quantity*o.Unit.Volume() - gold
+	if free := ship.FreeCapacity() - space[ship.Id]; need > free {
+		return nil, fmt.Errorf("unit %d needs %d cargo space for %s, has %d", o.Id, need, o.Unit, free)
+	}
+	if err := ship.Take(units.Unit{Name: "GOLD"}, gold); err != nil {
+		return nil, err
+	}
+	if need > 0 {
+		space[ship.Id] += need
+	}
+	return &offer{handle: po.Handle, nation: po.Nation, line: o.Line, unit: ship, qty: o.Quantity, price: o.Bid, held: gold}, nil
+}
+
+// ask validates a Sell order and holds the seller's goods.
+func (e *Engine) ask(po *Orders, o *Sell) (*offer, error) {
+	ship, err := e.ownedUnit(po.Nation, o.Id)
+	if err != nil {
+		return nil, err
+	} else if err = e.canTrade(po.Nation, ship); err != nil {
+		return nil, err
+	} else if o.Quantity < 1 {
+		return nil, fmt.Errorf("quantity must be positive")
+	} else if !(o.Ask > 0) {
+		return nil, fmt.Errorf("ask must be positive")
+	} else if o.Unit.Name == "GOLD" {
+		return nil, fmt.Errorf("gold can't be sold")
+	}
+	switch o.Unit.Name {
+	case "RESEARCH":
+		n, ok := e.Nations[po.Nation]
+		if !ok || n.Research < o.Quantity {
+			return nil, fmt.Errorf("nation does not have %d research", o.Quantity)
+		}
+		n.Research -= o.Quantity
+	case "TL":
+		// selling a tech level teaches it to the buyer without losing it
+		if o.Quantity != 1 {
+			return nil, fmt.Errorf("%s can only be sold once", o.Unit)
+		} else if tl := e.techLevel(po.Nation); o.Unit.TechLevel > tl {
+			return nil, fmt.Errorf("nation is at tech level %d and can't sell %s", tl, o.Unit)
+		}
+	default:
+		if err := ship.Take(o.Unit, o.Quantity); err != nil {
+			return nil, err
+		}
+	}
+	return &offer{handle: po.Handle, nation: po.Nation, line: o.Line, unit: ship, qty: o.Quantity, price: o.Ask}, nil
+}
+
+// clear matches the bids and asks for a single unit and returns the
+// goods and gold that are left over.
+func (e *Engine) clear(unit units.Unit, bids, asks []*offer) *Price {
+	p := &Price{Turn: e.Game.Turn, Unit: unit}
+	for _, of := range bids {
+		p.Bids += of.qty
+		if of.price > p.HighBid {
+			p.HighBid = of.price
+		}
+	}
+	for _, of := range asks {
+		p.Asks += of.qty
+		if p.LowAsk == 0 || of.price < p.LowAsk {
+			p.LowAsk = of.price
+		}
+	}
+
+	sort.SliceStable(bids, func(i, j int) bool {
+		if bids[i].price != bids[j].price {
+			return bids[i].price > bids[j].price
+		}
+		return byHandleAndLine(bids[i], bids[j])
+	})
+	sort.SliceStable(asks, func(i, j int) bool {
+		if asks[i].price != asks[j].price {
+			return asks[i].price < asks[j].price
+		}
+		return byHandleAndLine(asks[i], asks[j])
+	})
+
+	// find the quantity traded and the marginal bid and ask. A bid stops
+	// the matching if it is below an ask that has already been matched,
+	// so that a single price can settle every trade.
+	type fill struct {
+		bid, ask *offer
+		qty      int
+	}
+	var fills []fill
+	var marginalBid, marginalAsk float64
+	for _, bid := range bids {
+		if bid.price < marginalAsk {
+			break
+		}
+		for _, ask := range asks {
+			if bid.qty == 0 || ask.price > bid.price {
+				break
+			} else if ask.qty == 0 || ask.nation == bid.nation {
+				continue
+			}
+			qty := bid.qty
+			if ask.qty < qty {
+				qty = ask.qty
+			}
+			fills = append(fills, fill{bid: bid, ask: ask, qty: qty})
+			marginalBid = bid.price
+			if ask.price > marginalAsk {
+				marginalAsk = ask.price
+			}
+			bid.qty, ask.qty = bid.qty-qty, ask.qty-qty
+			p.Traded += qty
+		}
+	}
+	if p.Traded != 0 {
+		p.Price = (marginalBid + marginalAsk) / 2
+	}
+
+	// settle every trade at the clearing price. Each buyer pays the
+	// rounded cost of everything it bought, which is never more than the
+	// gold held for its bid, and each seller is paid its part of that.
+	bought := make(map[*offer]int)
+	for _, f := range fills {
+		before := int(math.Round(float64(bought[f.bid]) * p.Price))
+		bought[f.bid] += f.qty
+		gold := int(math.Round(float64(bought[f.bid])*p.Price)) - before
+		f.bid.held -= gold
+		e.give(f.bid.unit, unit, f.qty)
+		f.ask.unit.AddResource("GOLD", gold)
+	}
+
+	// return whatever is left to the players
+	for _, of := range bids {
+		if of.held != 0 {
+			of.unit.AddResource("GOLD", of.held)
+		}
+	}
+	for _, of := range asks {
+		if of.qty != 0 && unit.Name != "TL" {
+			e.give(of.unit, unit, of.qty)
+		}
+	}
+	return p
+}
+
+// give adds units to a ship or colony. Research and tech levels
+// are given to the nation that owns it instead.
+func (e *Engine) give(s *ships.Ship, unit units.Unit, qty int) {
+	if !isKnowledge(unit) {
+		s.Add(unit, qty)
+		return
+	}
+	n, ok := e.Nations[s.Owner]
+	if !ok {
+		return
+	}
+	switch unit.Name {
+	case "RESEARCH":
+		n.Research += qty
+	case "TL":
+		if unit.TechLevel > n.TechLevel {
+			n.TechLevel = unit.TechLevel
+		}
+	}
+}
+
+// byHandleAndLine breaks ties between offers at the same price.
+func byHandleAndLine(a, b *offer) bool {
+	if a.handle != b.handle {
+		return a.handle < b.handle
+	}
+	return a.line < b.line
+}
+
+// canTrade returns an error if the unit is in an orbit controlled by
+// another nation that has not granted the nation the right to trade.
+func (e *Engine) canTrade(nation string, unit *ships.Ship) error {
+	orbit, err := e.orbitAt(unit.Location)
+	if err != nil {
+		// units in deep space are not in anyone's orbit
+		return nil
+	}
+	return e.canUseOrbit(nation, orbit, "TRADE")
+}
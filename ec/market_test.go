@@ -0,0 +1,176 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package ec
+
+import (
+	"github.com/mdhender/wraithh/models/coordinates"
+	"github.com/mdhender/wraithh/models/orders"
+	"github.com/mdhender/wraithh/models/units"
+	"testing"
+)
+
+// testMarket returns an engine with three nations, each with ships
+// carrying 1,000 gold and 100 consumer goods, and orders for each nation.
+// Ship 10 belongs to alice, 20 and 21 to bob, and 30 to carol.
+func testMarket() (*Engine, map[string]*Orders) {
+	e := testEngine("1", "2", "3")
+	orbit := coordinates.Coordinates{System: "A", Orbit: 1}
+	for id, owner := range map[int]string{10: "1", 20: "2", 21: "2", 30: "3"} {
+		s := testShip(e, id, owner, orbit, 0)
+		s.Population = nil
+		s.AddInstalled(units.Unit{Name: "SU", TechLevel: 1}, 1000)
+		s.AddResource("GOLD", 1000)
+		s.AddStored(units.Unit{Name: "CNGD"}, 100)
+	}
+	// carol's orders are first so that ties aren't broken by accident
+	return e, map[string]*Orders{
+		"carol": {Validated: true, Handle: "carol", Nation: "3"},
+		"bob":   {Validated: true, Handle: "bob", Nation: "2"},
+		"alice": {Validated: true, Handle: "alice", Nation: "1"},
+	}
+}
+
+func TestMarketClearing(t *testing.T) {
+	cngd := units.Unit{Name: "CNGD"}
+	type holding struct{ gold, cngd int }
+	for _, tc := range []struct {
+		name   string
+		orders map[string][]orders.Order
+		traded int
+		price  float64
+		want   map[string]holding // what each ship ends up with
+	}{
+		{
+			name: "tie broken by handle",
+			orders: map[string][]orders.Order{
+				"alice": {&Buy{Line: 1, Id: 10, Quantity: 10, Unit: cngd, Bid: 10}},
+				"bob":   {&Sell{Line: 1, Id: 20, Quantity: 10, Unit: cngd, Ask: 5}},
+				"carol": {&Sell{Line: 1, Id: 30, Quantity: 10, Unit: cngd, Ask: 5}},
+			},
+			traded: 10, price: 7.5,
+			want: map[string]holding{"10": {925, 110}, "20": {1075, 90}, "30": {1000, 100}},
+		},
+		{
+			name: "tie broken by line",
+			orders: map[string][]orders.Order{
+				"alice": {&Buy{Line: 1, Id: 10, Quantity: 15, Unit: cngd, Bid: 10}},
+				"bob": {
+					&Sell{Line: 3, Id: 20, Quantity: 10, Unit: cngd, Ask: 5},
+					&Sell{Line: 2, Id: 21, Quantity: 10, Unit: cngd, Ask: 5},
+				},
+			},
+			traded: 15, price: 7.5,
+			// 15 units cost 112.5, rounded to 113
+			want: map[string]holding{"10": {887, 115}, "20": {1038, 95}, "21": {1075, 90}},
+		},
+		{
+			name: "partial fill",
+			orders: map[string][]orders.Order{
+				"alice": {&Buy{Line: 1, Id: 10, Quantity: 20, Unit: cngd, Bid: 10}},
+				"bob":   {&Sell{Line: 1, Id: 20, Quantity: 5, Unit: cngd, Ask: 4}},
+			},
+			traded: 5, price: 7,
+			want: map[string]holding{"10": {965, 105}, "20": {1035, 95}},
+		},
+		{
+			name: "own ask is not matched",
+			orders: map[string][]orders.Order{
+				"alice": {
+					&Sell{Line: 1, Id: 10, Quantity: 10, Unit: cngd, Ask: 1},
+					&Buy{Line: 2, Id: 10, Quantity: 10, Unit: cngd, Bid: 10},
+				},
+				"bob": {&Sell{Line: 1, Id: 20, Quantity: 10, Unit: cngd, Ask: 5}},
+			},
+			traded: 10, price: 7.5,
+			want: map[string]holding{"10": {925, 110}, "20": {1075, 90}},
+		},
+		{
+			name: "no match",
+			orders: map[string][]orders.Order{
+				"alice": {&Buy{Line: 1, Id: 10, Quantity: 10, Unit: cngd, Bid: 4}},
+				"bob":   {&Sell{Line: 1, Id: 20, Quantity: 10, Unit: cngd, Ask: 5}},
+			},
+			want: map[string]holding{"10": {1000, 100}, "20": {1000, 100}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			e, sets := testMarket()
+			var list []*Orders
+			for _, handle := range []string{"carol", "bob", "alice"} {
+				po := sets[handle]
+				po.Orders = tc.orders[handle]
+				list = append(list, po)
+			}
+
+			if err := marketPhase(e, list); err != nil {
+				t.Fatalf("market: %v", err)
+			}
+			for _, po := range list {
+				for _, r := range po.Results {
+					if r.Error != nil {
+						t.Errorf("%s: line %d: %v", po.Handle, r.Line, r.Error)
+					}
+				}
+			}
+			if len(e.Market) != 1 {
+				t.Fatalf("market: got %d prices, want 1", len(e.Market))
+			}
+			if p := e.Market[0]; p.Traded != tc.traded || p.Price != tc.price {
+				t.Errorf("market: got %d traded at %g, want %d at %g", p.Traded, p.Price, tc.traded, tc.price)
+			}
+			for id, want := range tc.want {
+				s := e.Ships[id]
+				if got := (holding{s.Resources["GOLD"], s.Stored[cngd]}); got != want {
+					t.Errorf("ship %s: got %+v, want %+v", id, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMarketRejectsNonPositiveQuantities(t *testing.T) {
+	research := units.Unit{Name: "RESEARCH"}
+	cngd := units.Unit{Name: "CNGD"}
+	for _, tc := range []struct {
+		name  string
+		order orders.Order
+	}{
+		{name: "sell negative research", order: &Sell{Line: 1, Id: 20, Quantity: -5000, Unit: research, Ask: 1}},
+		{name: "sell zero research", order: &Sell{Line: 1, Id: 20, Quantity: 0, Unit: research, Ask: 1}},
+		{name: "sell negative goods", order: &Sell{Line: 1, Id: 20, Quantity: -10, Unit: cngd, Ask: 1}},
+		{name: "sell zero tech level", order: &Sell{Line: 1, Id: 20, Quantity: 0, Unit: units.Unit{Name: "TL", TechLevel: 1}, Ask: 1}},
+		{name: "buy negative goods", order: &Buy{Line: 1, Id: 20, Quantity: -10, Unit: cngd, Bid: 1}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			e, sets := testMarket()
+			e.Nations["1"].Research, e.Nations["2"].Research = 100, 100
+			// alice bids for everything bob could sell
+			sets["alice"].Orders = []orders.Order{
+				&Buy{Line: 1, Id: 10, Quantity: 10, Unit: research, Bid: 1},
+				&Buy{Line: 2, Id: 10, Quantity: 10, Unit: cngd, Bid: 1},
+			}
+			sets["bob"].Orders = []orders.Order{tc.order}
+
+			if err := marketPhase(e, []*Orders{sets["alice"], sets["bob"]}); err != nil {
+				t.Fatalf("market: %v", err)
+			}
+			if r := sets["bob"].Results; len(r) != 1 || r[0].Error == nil {
+				t.Fatalf("order was accepted")
+			}
+			for _, p := range e.Market {
+				if p.Asks < 0 || p.Bids < 0 || p.Traded != 0 {
+					t.Errorf("%s: got %d bids, %d asks, %d traded", p.Unit, p.Bids, p.Asks, p.Traded)
+				}
+			}
+			if e.Nations["1"].Research != 100 || e.Nations["2"].Research != 100 {
+				t.Errorf("research: got %d and %d, want 100 and 100", e.Nations["1"].Research, e.Nations["2"].Research)
+			}
+			for _, id := range []string{"10", "20"} {
+				if s := e.Ships[id]; s.Resources["GOLD"] != 1000 || s.Stored[cngd] != 100 {
+					t.Errorf("ship %s: got %d gold and %d goods", id, s.Resources["GOLD"], s.Stored[cngd])
+				}
+			}
+		})
+	}
+}
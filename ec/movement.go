@@ -0,0 +1,159 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package ec
+
+import (
+	"fmt"
+	"github.com/mdhender/wraithh/models/ships"
+	"github.com/mdhender/wraithh/models/units"
+	"math"
+)
+
+const (
+	// hyperThrust is the mass that a single HDRV can jump, per tech level.
+	hyperThrust = 1_000
+	// spaceThrust is the mass that a single SDRV can move, per tech level.
+	spaceThrust = 250
+	// jumpFuel is the mass that one unit of fuel will jump one light year.
+	jumpFuel = 1_000
+	// moveFuel is the mass that one unit of fuel will move one orbit.
+	moveFuel = 5_000
+	// starDistance is the distance charged for a jump between two stars
+	// in the same system.
+	starDistance = 1.0
+)
+
+// movementPhase moves ships between orbits and jumps them between systems.
+// A ship may only move once per turn, no matter how many orders it is given.
+func movementPhase(e *Engine, orders []*Orders) error {
+	// moved holds the ships that have moved this turn
+	moved := make(map[string]bool)
+	for _, po := range orders {
+		for _, order := range po.Orders {
+			var line int
+			var err error
+			switch o := order.(type) {
+			case *Move:
+				line, err = o.Line, e.move(po.Nation, o, moved)
+			case *Jump:
+				line, err = o.Line, e.jump(po.Nation, o, moved)
+			default:
+				continue
+			}
+			e.result(po, line, order, err)
+		}
+	}
+	return nil
+}
+
+// move changes the orbit of a ship inside its current system.
+func (e *Engine) move(nation string, o *Move, moved map[string]bool) error {
+	ship, err := e.movingShip(nation, o.Id, moved)
+	if err != nil {
+		return err
+	}
+	star, ok := e.Stars[ship.Location.StarLocation().String()]
+	if !ok {
+		return fmt.Errorf("ship %d is not in a system", o.Id)
+	}
+	if !(0 < o.Orbit && o.Orbit < len(star.Orbits)) {
+		return fmt.Errorf("orbit %d does not exist", o.Orbit)
+	} else if o.Orbit == ship.Location.Orbit {
+		return fmt.Errorf("ship %d is already in orbit %d", o.Id, o.Orbit)
+	}
+
+	if thrust := enginesThrust(ship.Installed, "SDRV", spaceThrust); thrust == 0 {
+		return fmt.Errorf("ship %d has no space drives", o.Id)
+	} else if thrust < ship.Mass() {
+		return fmt.Errorf("ship %d needs %d thrust, has %d", o.Id, ship.Mass(), thrust)
+	}
+	distance := ship.Location.Orbit - o.Orbit
+	if distance < 0 {
+		distance = -distance
+	}
+	fuel := int(math.Ceil(float64(distance*ship.Mass()) / moveFuel))
+	if fuel > ship.Resources["FUEL"] {
+		return fmt.Errorf("ship %d needs %d fuel, has %d", o.Id, fuel, ship.Resources["FUEL"])
+	}
+
+	ship.AddResource("FUEL", -fuel)
+	ship.Location = star.Orbits[o.Orbit].Location
+	moved[ship.Id] = true
+	return nil
+}
+
+// jump moves a ship from its current system to another system, or to
+// another star in its current system.
+func (e *Engine) jump(nation string, o *Jump, moved map[string]bool) error {
+	ship, err := e.movingShip(nation, o.Id, moved)
+	if err != nil {
+		return err
+	}
+	if _, ok := e.Systems[o.Location.SystemLocation().String()]; !ok {
+		return fmt.Errorf("system %s does not exist", o.Location.SystemLocation())
+	}
+	star, ok := e.Stars[o.Location.StarLocation().String()]
+	if !ok {
+		return fmt.Errorf("star %s does not exist", o.Location.StarLocation())
+	}
+	if !(0 <= o.Location.Orbit && o.Location.Orbit < len(star.Orbits)) {
+		return fmt.Errorf("orbit %d does not exist", o.Location.Orbit)
+	}
+	distance := ship.Location.DistanceTo(o.Location)
+	if distance == 0 {
+		if ship.Location.StarLocation() == star.Location {
+			return fmt.Errorf("ship %d is already at star %s", o.Id, star.Location)
+		}
+		distance = starDistance
+	}
+
+	if thrust := enginesThrust(ship.Installed, "HDRV", hyperThrust); thrust == 0 {
+		return fmt.Errorf("ship %d has no hyper engines", o.Id)
+	} else if thrust < ship.Mass() {
+		return fmt.Errorf("ship %d needs %d thrust, has %d", o.Id, ship.Mass(), thrust)
+	}
+	fuel := int(math.Ceil(distance * float64(ship.Mass()) / jumpFuel))
+	if fuel > ship.Resources["FUEL"] {
+		return fmt.Errorf("ship %d needs %d fuel, has %d", o.Id, fuel, ship.Resources["FUEL"])
+	}
+
+	ship.AddResource("FUEL", -fuel)
+	ship.Location = star.Location
+	if o.Location.Orbit != 0 {
+		ship.Location = star.Orbits[o.Location.Orbit].Location
+	}
+	moved[ship.Id] = true
+	return nil
+}
+
+// movingShip returns the ship being moved if the nation owns it and it
+// has not already moved this turn.
+func (e *Engine) movingShip(nation string, id int, moved map[string]bool) (*ships.Ship, error) {
+	ship, err := e.ownedUnit(nation, id)
+	if err != nil {
+		return nil, err
+	} else if ship.Kind != ships.Vessel {
+		return nil, fmt.Errorf("unit %d is a colony and can't move", id)
+	} else if moved[ship.Id] {
+		return nil, fmt.Errorf("ship %d has already moved this turn", id)
+	}
+	return ship, nil
+}
+
+// enginesThrust returns the total thrust of the named engines.
+// Thrust scales with the tech level of each engine.
+func enginesThrust(engines map[units.Unit]int, name string, thrust int) int {
+	var total int
+	for unit, qty := range engines {
+		if unit.Name != name {
+			continue
+		}
+		tl := unit.TechLevel
+		if tl < 1 {
+			tl = 1
+		}
+		total += qty * tl * thrust
+	}
+	return total
+}
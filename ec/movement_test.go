@@ -0,0 +1,62 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package ec
+
+import (
+	"github.com/mdhender/wraithh/models/coordinates"
+	"github.com/mdhender/wraithh/models/units"
+	"testing"
+)
+
+func TestJump(t *testing.T) {
+	start := coordinates.Coordinates{System: "A", Orbit: 1}
+	for _, tc := range []struct {
+		name     string
+		hdrv     int // hyper engines installed
+		su       int // structural units installed
+		fuel     int
+		to       coordinates.Coordinates
+		wantErr  bool
+		wantFuel int // fuel left after the jump
+	}{
+		// mass is 2*25 + 100 + 50 = 200, so a jump of 5 light years costs 1 fuel
+		{name: "another system", hdrv: 2, su: 100, fuel: 50, to: coordinates.Coordinates{X: 3, Y: 4, System: "A", Orbit: 2}, wantFuel: 49},
+		{name: "another star in the system", hdrv: 2, su: 100, fuel: 50, to: coordinates.Coordinates{System: "B", Orbit: 2}, wantFuel: 49},
+		{name: "same star", hdrv: 2, su: 100, fuel: 50, to: coordinates.Coordinates{System: "A", Orbit: 3}, wantErr: true},
+		{name: "unknown system", hdrv: 2, su: 100, fuel: 50, to: coordinates.Coordinates{X: 9, Y: 9, Z: 9, System: "A"}, wantErr: true},
+		{name: "unknown star", hdrv: 2, su: 100, fuel: 50, to: coordinates.Coordinates{System: "C"}, wantErr: true},
+		{name: "no hyper engines", su: 100, fuel: 50, to: coordinates.Coordinates{X: 3, Y: 4, System: "A"}, wantErr: true},
+		{name: "not enough thrust", hdrv: 1, su: 1000, fuel: 50, to: coordinates.Coordinates{X: 3, Y: 4, System: "A"}, wantErr: true},
+		{name: "not enough fuel", hdrv: 2, su: 100, fuel: 0, to: coordinates.Coordinates{X: 3, Y: 4, System: "A"}, wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			e := testEngine("1")
+			testStar(e, coordinates.Coordinates{System: "B"})
+			testStar(e, coordinates.Coordinates{X: 3, Y: 4, System: "A"})
+			ship := testShip(e, 10, "1", start, 0)
+			ship.Population = nil
+			ship.AddInstalled(units.Unit{Name: "HDRV", TechLevel: 1}, tc.hdrv)
+			ship.AddInstalled(units.Unit{Name: "SU", TechLevel: 1}, tc.su)
+			ship.AddResource("FUEL", tc.fuel)
+
+			err := e.jump("1", &Jump{Line: 1, Id: 10, Location: tc.to}, make(map[string]bool))
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("jump: got nil, want error")
+				} else if ship.Location != start || ship.Resources["FUEL"] != tc.fuel {
+					t.Errorf("failed jump changed the ship: location %s fuel %d", ship.Location, ship.Resources["FUEL"])
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("jump: %v", err)
+			}
+			if ship.Location != tc.to {
+				t.Errorf("location: got %s, want %s", ship.Location, tc.to)
+			}
+			if got := ship.Resources["FUEL"]; got != tc.wantFuel {
+				t.Errorf("fuel: got %d, want %d", got, tc.wantFuel)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package ec
+
+import (
+	"fmt"
+	"github.com/mdhender/wraithh/models/coordinates"
+)
+
+// Article is a news article published in a system.
+type Article struct {
+	Turn      int
+	Location  coordinates.Coordinates // system the article was published in
+	Article   string
+	Signature string
+}
+
+// NewsPhase publishes news articles.
+func (e *Engine) NewsPhase(orders *Orders) error {
+	for _, order := range orders.Orders {
+		o, ok := order.(*News)
+		if !ok {
+			continue
+		}
+		location := o.Location.SystemLocation()
+		if _, ok := e.Systems[location.String()]; !ok {
+			e.result(orders, o.Line, o, fmt.Errorf("system %s does not exist", location))
+			continue
+		}
+		e.News = append(e.News, &Article{
+			Turn:      e.Game.Turn,
+			Location:  location,
+			Article:   o.Article,
+			Signature: o.Signature,
+		})
+		e.result(orders, o.Line, o, nil)
+	}
+	return nil
+}
@@ -13,10 +13,13 @@ import (
 type Orders struct {
 	Validated bool
 	Handle    string
+	Player    string // id of the player, set when the secret is verified
+	Nation    string // id of the player's nation, set when the orders are validated
 	Game      string
 	Turn      int
 	Secret    *Secret
 	Orders    []orders.Order
+	Results   []*Result // outcome of each order, sorted by line number
 	Error     error
 }
 
@@ -309,6 +312,16 @@ type RetoolFactoryGroup struct {
 
 func (o *RetoolFactoryGroup) Execute() error { panic("!") }
 
+// The Rejected order type captures orders that failed to parse.
+// They are reported back to the player and never executed.
+type Rejected struct {
+	Line   int
+	Source string  // original text of the order
+	Errors []error // reasons the order was rejected
+}
+
+func (o *Rejected) Execute() error { panic("!") }
+
 type Revoke struct {
 	Line     int
 	Location coordinates.Coordinates // coordinates of system and orbit
@@ -477,3 +490,114 @@ type Unknown struct {
 }
 
 func (o *Unknown) Execute() error { panic("!") }
+
+// lineOf returns the line number of the order in the player's file.
+func lineOf(order orders.Order) int {
+	switch o := order.(type) {
+	case *Abandon:
+		return o.Line
+	case *AssembleFactoryGroup:
+		return o.Line
+	case *AssembleMineGroup:
+		return o.Line
+	case *AssembleUnit:
+		return o.Line
+	case *Bombard:
+		return o.Line
+	case *Buy:
+		return o.Line
+	case *CheckRebels:
+		return o.Line
+	case *Claim:
+		return o.Line
+	case *ConvertRebels:
+		return o.Line
+	case *CounterAgents:
+		return o.Line
+	case *Discharge:
+		return o.Line
+	case *Draft:
+		return o.Line
+	case *ExpandFactoryGroup:
+		return o.Line
+	case *ExpandMineGroup:
+		return o.Line
+	case *Grant:
+		return o.Line
+	case *InciteRebels:
+		return o.Line
+	case *Invade:
+		return o.Line
+	case *Jump:
+		return o.Line
+	case *Move:
+		return o.Line
+	case *Name:
+		return o.Line
+	case *NameUnit:
+		return o.Line
+	case *News:
+		return o.Line
+	case *PayAll:
+		return o.Line
+	case *PayLocal:
+		return o.Line
+	case *Probe:
+		return o.Line
+	case *ProbeSystem:
+		return o.Line
+	case *Raid:
+		return o.Line
+	case *RationAll:
+		return o.Line
+	case *RationLocal:
+		return o.Line
+	case *RecycleFactoryGroup:
+		return o.Line
+	case *RecycleMineGroup:
+		return o.Line
+	case *RecycleUnit:
+		return o.Line
+	case *RetoolFactoryGroup:
+		return o.Line
+	case *Rejected:
+		return o.Line
+	case *Revoke:
+		return o.Line
+	case *ScrapFactoryGroup:
+		return o.Line
+	case *ScrapMineGroup:
+		return o.Line
+	case *ScrapUnit:
+		return o.Line
+	case *Secret:
+		return o.Line
+	case *Sell:
+		return o.Line
+	case *Setup:
+		return o.Line
+	case *StealSecrets:
+		return o.Line
+	case *StoreFactoryGroup:
+		return o.Line
+	case *StoreMineGroup:
+		return o.Line
+	case *StoreUnit:
+		return o.Line
+	case *SupportAttack:
+		return o.Line
+	case *SupportDefend:
+		return o.Line
+	case *SuppressAgents:
+		return o.Line
+	case *Survey:
+		return o.Line
+	case *SurveySystem:
+		return o.Line
+	case *Transfer:
+		return o.Line
+	case *Unknown:
+		return o.Line
+	}
+	return 0
+}
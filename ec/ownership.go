@@ -0,0 +1,241 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package ec
+
+import (
+	"fmt"
+	"github.com/mdhender/wraithh/models/coordinates"
+	"github.com/mdhender/wraithh/models/orbits"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// claim is a nation's attempt to take control of an orbit.
+type claim struct {
+	po    *Orders
+	order *Claim
+}
+
+// ownershipPhase abandons and claims orbits, then grants and revokes
+// the rights to use them.
+//
+// Orbits are abandoned first so that they can be claimed by another
+// nation in the same turn. When several nations claim the same orbit,
+// the nation with the most soldiers in orbit wins. If the top nations
+// are tied, nobody gets the orbit. Rights are granted last so that a
+// nation can grant them in the turn it takes control of an orbit.
+func ownershipPhase(e *Engine, orders []*Orders) error {
+	for _, po := range orders {
+		for _, order := range po.Orders {
+			if o, ok := order.(*Abandon); ok {
+				e.result(po, o.Line, o, e.abandon(po.Nation, o.Location))
+			}
+		}
+	}
+
+	// claims holds the valid claims for each orbit, keyed by orbit id
+	claims := make(map[string][]*claim)
+	for _, po := range orders {
+		for _, order := range po.Orders {
+			o, ok := order.(*Claim)
+			if !ok {
+				continue
+			}
+			orbit, err := e.canClaim(po.Nation, o)
+			if err != nil {
+				e.result(po, o.Line, o, err)
+				continue
+			}
+			claims[orbit.Id] = append(claims[orbit.Id], &claim{po: po, order: o})
+		}
+	}
+
+	var ids []string
+	for id := range claims {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		orbit := e.Orbits[id]
+		winner, strongest, tied := "", -1, false
+		for _, c := range claims[id] {
+			if c.po.Nation == winner {
+				continue
+			}
+			if n := e.soldiersInOrbit(c.po.Nation, orbit.Location); n > strongest {
+				winner, strongest, tied = c.po.Nation, n, false
+			} else if n == strongest {
+				tied = true
+			}
+		}
+		if tied {
+			winner = ""
+		}
+		for _, c := range claims[id] {
+			var err error
+			if winner == "" {
+				err = fmt.Errorf("orbit %s is contested", orbit.Id)
+			} else if c.po.Nation != winner {
+				err = fmt.Errorf("orbit %s was claimed by nation %s", orbit.Id, winner)
+			}
+			e.result(c.po, c.order.Line, c.order, err)
+		}
+		if winner != "" {
+			control(orbit, winner)
+		}
+	}
+
+	for _, po := range orders {
+		for _, order := range po.Orders {
+			var line int
+			var err error
+			switch o := order.(type) {
+			case *Grant:
+				line, err = o.Line, e.permit(po.Nation, o.Location, o.Kind, o.TargetId, false)
+			case *Revoke:
+				line, err = o.Line, e.permit(po.Nation, o.Location, o.Kind, o.TargetId, true)
+			default:
+				continue
+			}
+			e.result(po, line, order, err)
+		}
+	}
+	return nil
+}
+
+// canClaim returns the orbit if the nation may claim it. The unit must
+// be in the orbit, and the orbit must not be held by another nation
+// that still has a unit there.
+func (e *Engine) canClaim(nation string, o *Claim) (*orbits.Orbit, error) {
+	unit, err := e.ownedUnit(nation, o.Id)
+	if err != nil {
+		return nil, err
+	}
+	orbit, err := e.orbitAt(o.Location)
+	if err != nil {
+		return nil, err
+	} else if !sameOrbit(unit.Location, orbit.Location) {
+		return nil, fmt.Errorf("unit %d is not in orbit %s", o.Id, orbit.Id)
+	} else if orbit.ControlledBy == nation {
+		return nil, fmt.Errorf("orbit %s is already yours", orbit.Id)
+	} else if orbit.ControlledBy != "" && e.present(orbit.ControlledBy, orbit.Location) {
+		return nil, fmt.Errorf("orbit %s is controlled by nation %s", orbit.Id, orbit.ControlledBy)
+	}
+	return orbit, nil
+}
+
+// abandon gives up control of an orbit and its deposits.
+func (e *Engine) abandon(nation string, location coordinates.Coordinates) error {
+	orbit, err := e.orbitAt(location)
+	if err != nil {
+		return err
+	} else if orbit.ControlledBy != nation {
+		return fmt.Errorf("orbit %s is not yours", orbit.Id)
+	}
+	control(orbit, "")
+	return nil
+}
+
+// control hands the orbit and its deposits to the nation.
+// Rights granted by the previous controller are lost.
+func control(orbit *orbits.Orbit, nation string) {
+	orbit.ControlledBy, orbit.Grants = nation, nil
+	for i := range orbit.Deposits {
+		orbit.Deposits[i].ControlledBy = nation
+	}
+}
+
+// Permit is a right to use an orbit granted or revoked this turn.
+type Permit struct {
+	Location coordinates.Coordinates
+	Kind     string // COLONIZE or TRADE
+	Granter  string // nation controlling the orbit
+	Grantee  string // nation given or losing the right
+	Revoked  bool
+}
+
+// permit grants or revokes a nation's right to use an orbit that the
+// granter controls.
+func (e *Engine) permit(nation string, location coordinates.Coordinates, kind string, targetId int, revoke bool) error {
+	orbit, err := e.orbitAt(location)
+	if err != nil {
+		return err
+	} else if orbit.ControlledBy != nation {
+		return fmt.Errorf("orbit %s is not yours", orbit.Id)
+	} else if kind != "COLONIZE" && kind != "TRADE" {
+		return fmt.Errorf("unknown right %q", kind)
+	}
+	target := strconv.Itoa(targetId)
+	if _, ok := e.Nations[target]; !ok || target == nation {
+		return fmt.Errorf("nation %d does not exist", targetId)
+	}
+	granted := contains(orbit.Grants[kind], target)
+	if revoke {
+		if !granted {
+			return fmt.Errorf("nation %d does not have the right to %s", targetId, strings.ToLower(kind))
+		}
+		var list []string
+		for _, id := range orbit.Grants[kind] {
+			if id != target {
+				list = append(list, id)
+			}
+		}
+		orbit.Grants[kind] = list
+		if len(list) == 0 {
+			delete(orbit.Grants, kind)
+		}
+	} else {
+		if granted {
+			return fmt.Errorf("nation %d already has the right to %s", targetId, strings.ToLower(kind))
+		}
+		if orbit.Grants == nil {
+			orbit.Grants = make(map[string][]string)
+		}
+		orbit.Grants[kind] = append(orbit.Grants[kind], target)
+		sort.Strings(orbit.Grants[kind])
+	}
+	e.Permits = append(e.Permits, &Permit{Location: orbit.Location, Kind: kind, Granter: nation, Grantee: target, Revoked: revoke})
+	return nil
+}
+
+// present returns true if the nation has a ship or colony in the orbit.
+func (e *Engine) present(nation string, location coordinates.Coordinates) bool {
+	for _, s := range e.ownedUnits(nation) {
+		if sameOrbit(s.Location, location) {
+			return true
+		}
+	}
+	return false
+}
+
+// soldiersInOrbit returns the soldiers on the nation's ships and
+// colonies in the orbit.
+func (e *Engine) soldiersInOrbit(nation string, location coordinates.Coordinates) int {
+	var total int
+	for _, s := range e.ownedUnits(nation) {
+		if sameOrbit(s.Location, location) {
+			total += s.Population["SLD"]
+		}
+	}
+	return total
+}
+
+// canUseOrbit returns an error if the orbit is controlled by another
+// nation that has not granted the nation the right to use it.
+func (e *Engine) canUseOrbit(nation string, orbit *orbits.Orbit, right string) error {
+	if orbit.ControlledBy == "" || orbit.ControlledBy == nation || contains(orbit.Grants[right], nation) {
+		return nil
+	}
+	return fmt.Errorf("orbit %s is controlled by nation %s", orbit.Id, orbit.ControlledBy)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
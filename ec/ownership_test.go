@@ -0,0 +1,125 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package ec
+
+import (
+	"github.com/mdhender/wraithh/models/coordinates"
+	"github.com/mdhender/wraithh/models/knowledge"
+	"github.com/mdhender/wraithh/models/nations"
+	"github.com/mdhender/wraithh/models/orbits"
+	"github.com/mdhender/wraithh/models/orders"
+	"github.com/mdhender/wraithh/models/ships"
+	"github.com/mdhender/wraithh/models/systems"
+	"strconv"
+	"testing"
+)
+
+// testEngine returns an engine with a single system at the origin with
+// one star, and a nation at tech level 1 for each id.
+func testEngine(ids ...string) *Engine {
+	e := &Engine{
+		Nations:   make(map[string]*nations.Nation),
+		Knowledge: make(map[string]*knowledge.Knowledge),
+		Systems:   make(map[string]*systems.System),
+		Stars:     make(map[string]*systems.Star),
+		Ships:     make(map[string]*ships.Ship),
+		Colonies:  make(map[string]*ships.Ship),
+	}
+	for _, id := range ids {
+		e.Nations[id] = &nations.Nation{Id: id, TechLevel: 1}
+	}
+	testStar(e, coordinates.Coordinates{System: "A"})
+	return e
+}
+
+// testStar adds a star with ten terrestrial orbits to the engine,
+// creating its system if needed.
+func testStar(e *Engine, location coordinates.Coordinates) *systems.Star {
+	star := &systems.Star{Id: location.String(), Location: location}
+	for o := 1; o < len(star.Orbits); o++ {
+		location := star.Location
+		location.Orbit = o
+		star.Orbits[o] = orbits.Orbit{Id: location.String(), Location: location, Kind: orbits.Terrestrial}
+	}
+	e.Stars[star.Id] = star
+	id := location.SystemLocation().String()
+	sys, ok := e.Systems[id]
+	if !ok {
+		sys = &systems.System{Id: id, Location: location.SystemLocation()}
+		e.Systems[id] = sys
+	}
+	sys.Stars = append(sys.Stars, star.Id)
+	e.indexOrbits()
+	return star
+}
+
+// testShip adds a ship with soldiers to the engine.
+func testShip(e *Engine, id int, owner string, location coordinates.Coordinates, soldiers int) *ships.Ship {
+	s := &ships.Ship{
+		Id:         strconv.Itoa(id),
+		Owner:      owner,
+		Kind:       ships.Vessel,
+		Location:   location,
+		Population: map[string]int{"SLD": soldiers},
+	}
+	e.Ships[s.Id] = s
+	return s
+}
+
+func TestOwnershipPhaseClaims(t *testing.T) {
+	orbit := coordinates.Coordinates{System: "A", Orbit: 3}
+	for _, tc := range []struct {
+		name     string
+		soldiers map[string]int // soldiers of each nation claiming the orbit
+		holder   string         // nation controlling the orbit before the claims
+		want     string         // nation controlling the orbit after the claims
+	}{
+		{name: "single claim", soldiers: map[string]int{"1": 10}, want: "1"},
+		{name: "strongest wins", soldiers: map[string]int{"1": 10, "2": 20}, want: "2"},
+		{name: "tie", soldiers: map[string]int{"1": 20, "2": 20}, want: ""},
+		{name: "tie beaten by a stronger nation", soldiers: map[string]int{"1": 20, "2": 20, "3": 30}, want: "3"},
+		{name: "tie below the strongest", soldiers: map[string]int{"1": 30, "2": 10, "3": 10}, want: "1"},
+		{name: "holder keeps the orbit", soldiers: map[string]int{"1": 50}, holder: "3", want: "3"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			e := testEngine("1", "2", "3")
+			if tc.holder != "" {
+				id, _ := strconv.Atoi(tc.holder)
+				testShip(e, id, tc.holder, orbit, 1)
+				control(e.Orbits[orbit.String()], tc.holder)
+			}
+			var list []*Orders
+			for _, nation := range []string{"1", "2", "3"} {
+				soldiers, ok := tc.soldiers[nation]
+				if !ok {
+					continue
+				}
+				id, _ := strconv.Atoi(nation)
+				testShip(e, id, nation, orbit, soldiers)
+				list = append(list, &Orders{
+					Validated: true,
+					Handle:    "nation-" + nation,
+					Nation:    nation,
+					Orders:    []orders.Order{&Claim{Line: 1, Id: id, Location: orbit}},
+				})
+			}
+
+			if err := ownershipPhase(e, list); err != nil {
+				t.Fatalf("ownership: %v", err)
+			}
+			if got := e.Orbits[orbit.String()].ControlledBy; got != tc.want {
+				t.Errorf("controlled by: got %q, want %q", got, tc.want)
+			}
+			for _, po := range list {
+				if len(po.Results) != 1 {
+					t.Fatalf("nation %s: got %d results, want 1", po.Nation, len(po.Results))
+				}
+				won := po.Results[0].Error == nil
+				if want := po.Nation == tc.want; won != want {
+					t.Errorf("nation %s: claim succeeded %v, want %v: %v", po.Nation, won, want, po.Results[0].Error)
+				}
+			}
+		})
+	}
+}
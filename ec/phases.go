@@ -0,0 +1,118 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package ec
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mdhender/wraithh/models/orders"
+	"log"
+)
+
+// Phase is a single, named step in processing a turn.
+// Phases run in the order they are registered on the Engine.
+type Phase struct {
+	Name string
+	// Run executes the phase against every player's orders.
+	// The orders are sorted by handle, and order sets rejected by an
+	// earlier phase are not passed in.
+	Run func(e *Engine, orders []*Orders) error
+}
+
+// Result is the outcome of executing a single order.
+type Result struct {
+	Phase string // name of the phase that executed the order
+	Line  int    // line number of the order in the player's file
+	Order orders.Order
+	Error error // reason the order failed, nil if it succeeded
+}
+
+// Succeeded returns true if the order was executed without error.
+func (r *Result) Succeeded() bool {
+	return r.Error == nil
+}
+
+// errNotImplemented is the result for orders that no phase executes.
+var errNotImplemented = fmt.Errorf("order not implemented")
+
+// DefaultPhases returns the phases of a turn, in the order they must run.
+func DefaultPhases() []Phase {
+	return []Phase{
+		{Name: "secrets", Run: secretsPhase},
+		{Name: "ownership", Run: ownershipPhase},                // abandon, claim, grant, revoke
+		{Name: "combat", Run: combatPhase},                      // bombard, invade, raid, support
+		{Name: "espionage", Run: espionagePhase},                // spy missions
+		{Name: "setup", Run: perPlayer((*Engine).SetupPhase)},   // setup, transfer
+		{Name: "production", Run: productionPhase},              // assemble, expand, retool, recycle, scrap, store
+		{Name: "market", Run: marketPhase},                      // buy, sell
+		{Name: "movement", Run: movementPhase},                  // move, jump
+		{Name: "survey", Run: perPlayer((*Engine).SurveyPhase)}, // survey, probe
+		{Name: "population", Run: populationPhase},              // draft, discharge, pay, ration
+		{Name: "observe", Run: observePhase},                    // what every unit can see
+		{Name: "news", Run: perPlayer((*Engine).NewsPhase)},
+	}
+}
+
+// perPlayer adapts a phase that handles one player's orders at a time.
+func perPlayer(fn func(e *Engine, orders *Orders) error) func(e *Engine, orders []*Orders) error {
+	return func(e *Engine, orders []*Orders) error {
+		for _, po := range orders {
+			if err := fn(e, po); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func secretsPhase(e *Engine, orders []*Orders) error {
+	// validated holds the players that have a set of orders for the turn.
+	// Only the first set from each player is run.
+	validated := make(map[string]bool)
+	for _, po := range orders { // for each player orders
+		err := e.SecretsPhase(po)
+		if err != nil {
+			// any error with secrets means the order file should be skipped
+			po.Validated = false
+			if po.Error == nil {
+				po.Error = err
+			}
+		} else if po.Validated && validated[po.Player] {
+			po.Validated = false
+			po.Error = fmt.Errorf("duplicate orders for player")
+		}
+		if po.Validated {
+			validated[po.Player] = true
+		}
+		if po.Secret != nil {
+			e.result(po, po.Secret.Line, po.Secret, po.Error)
+		}
+		if po.Validated {
+			// orders that failed to parse are rejected before anything runs
+			for _, order := range po.Orders {
+				if o, ok := order.(*Rejected); ok {
+					e.result(po, o.Line, o, errors.Join(o.Errors...))
+				}
+			}
+		}
+		if po.Validated {
+			log.Printf("secrets: validated %s\n", po.Handle)
+		} else if po.Error == nil {
+			log.Printf("secrets: failed    %s\n", po.Handle)
+		} else {
+			log.Printf("secrets: failed    %s %v\n", po.Handle, po.Error)
+		}
+	}
+	return nil
+}
+
+// result records the outcome of an order in the current phase.
+func (e *Engine) result(po *Orders, line int, order orders.Order, err error) {
+	po.Results = append(po.Results, &Result{
+		Phase: e.phase,
+		Line:  line,
+		Order: order,
+		Error: err,
+	})
+}
@@ -0,0 +1,222 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package ec
+
+import (
+	"fmt"
+	"github.com/mdhender/wraithh/models/ships"
+	"github.com/mdhender/wraithh/models/units"
+	"math"
+)
+
+const (
+	// farmOutput is the FOOD that a single FARM grows each turn, per tech level.
+	farmOutput = 100
+	// peoplePerFood is the number of people that one FOOD feeds for a turn
+	// on full rations.
+	peoplePerFood = 4
+	// birthRate and deathRate are the fraction of the population
+	// born and dying each turn.
+	birthRate = 0.0025
+	deathRate = 0.0015
+	// starvationRate is the fraction of the unfed population that dies each turn.
+	starvationRate = 0.25
+)
+
+// PopulationPhase drafts, discharges, pays and rations the population.
+func (e *Engine) PopulationPhase(orders *Orders) error {
+	for _, order := range orders.Orders {
+		var line int
+		var err error
+		switch o := order.(type) {
+		case *Draft:
+			line, err = o.Line, e.draft(orders.Nation, o.Id, o.Quantity, "UNSK", o.Profession)
+		case *Discharge:
+			line, err = o.Line, e.draft(orders.Nation, o.Id, o.Quantity, o.Profession, "UNSK")
+		case *PayAll:
+			line, err = o.Line, e.pay(e.ownedUnits(orders.Nation), o.Profession, o.Rate)
+		case *PayLocal:
+			line, err = o.Line, e.payLocal(orders.Nation, o)
+		case *RationAll:
+			line, err = o.Line, e.ration(e.ownedUnits(orders.Nation), o.Rate)
+		case *RationLocal:
+			line, err = o.Line, e.rationLocal(orders.Nation, o)
+		default:
+			continue
+		}
+		e.result(orders, line, order, err)
+	}
+	return nil
+}
+
+// populationPhase runs every player's population orders, then feeds,
+// pays, grows and buries the population of every ship and colony.
+// Colonies that were short of food or pay grow restless.
+func populationPhase(e *Engine, orders []*Orders) error {
+	for _, po := range orders {
+		if err := e.PopulationPhase(po); err != nil {
+			return err
+		}
+	}
+	for _, s := range sortShips(e.Ships) {
+		populate(s)
+	}
+	for _, s := range sortShips(e.Colonies) {
+		fed, paid := populate(s)
+		e.agitate(s, fed, paid)
+	}
+	return nil
+}
+
+// draft moves people from one profession to another.
+func (e *Engine) draft(nation string, id, qty int, from, to string) error {
+	s, err := e.ownedUnit(nation, id)
+	if err != nil {
+		return err
+	} else if err = s.Take(units.Unit{Name: from}, qty); err != nil {
+		return err
+	}
+	s.AddPopulation(to, qty)
+	return nil
+}
+
+func (e *Engine) pay(list []*ships.Ship, profession string, rate float64) error {
+	if rate < 0 {
+		return fmt.Errorf("pay rate must not be negative")
+	}
+	for _, s := range list {
+		if s.Pay == nil {
+			s.Pay = make(map[string]float64)
+		}
+		s.Pay[profession] = rate
+	}
+	return nil
+}
+
+func (e *Engine) payLocal(nation string, o *PayLocal) error {
+	s, err := e.ownedUnit(nation, o.Id)
+	if err != nil {
+		return err
+	}
+	return e.pay([]*ships.Ship{s}, o.Profession, o.Rate)
+}
+
+func (e *Engine) ration(list []*ships.Ship, rate int) error {
+	if !(0 < rate && rate <= 200) {
+		return fmt.Errorf("rations must be between 1%% and 200%%")
+	}
+	for _, s := range list {
+		s.Rations = rate
+	}
+	return nil
+}
+
+func (e *Engine) rationLocal(nation string, o *RationLocal) error {
+	s, err := e.ownedUnit(nation, o.Id)
+	if err != nil {
+		return err
+	}
+	return e.ration([]*ships.Ship{s}, o.Rate)
+}
+
+// ownedUnits returns every ship and colony owned by the nation, sorted by id.
+func (e *Engine) ownedUnits(nation string) []*ships.Ship {
+	var list []*ships.Ship
+	for _, m := range []map[string]*ships.Ship{e.Ships, e.Colonies} {
+		for _, s := range sortShips(m) {
+			if s.Owner == nation {
+				list = append(list, s)
+			}
+		}
+	}
+	return list
+}
+
+// populate runs a turn of life on a ship or colony.
+//
+// Farms grow food and the population is fed and paid from the stores
+// on hand. Morale moves halfway towards a target set by how well the
+// population was fed and paid compared to full rations and standard pay.
+// Then people are born and die, with hungry populations dying faster.
+//
+// It returns the share of a full ration and of standard pay that the
+// population received.
+func populate(s *ships.Ship) (fed, pay float64) {
+	pop := s.TotalPopulation()
+	if pop == 0 {
+		return 1, 1
+	}
+
+	// grow food, up to the free cargo space
+	food := capacity(named(s.Installed, "FARM")) * farmOutput
+	if free := s.FreeCapacity(); food > free {
+		food = free
+	}
+	if food > 0 {
+		s.AddStored(units.Unit{Name: "FOOD"}, food)
+	}
+
+	// hand out rations
+	fullRation := float64(pop) / peoplePerFood
+	ration := int(math.Ceil(fullRation * float64(s.RationPct()) / 100))
+	if have := s.Stored[units.Unit{Name: "FOOD"}]; ration > have {
+		ration = have
+	}
+	s.AddStored(units.Unit{Name: "FOOD"}, -ration)
+	fed = float64(ration) / fullRation
+
+	// pay everyone, as far as the gold goes
+	var owed, standard float64
+	for code, qty := range s.Population {
+		owed += float64(qty) * s.PayRate(code)
+		standard += float64(qty) * ships.StandardPay[code]
+	}
+	paid := int(math.Ceil(owed))
+	if paid > s.Resources["GOLD"] {
+		paid = s.Resources["GOLD"]
+	}
+	s.AddResource("GOLD", -paid)
+	pay = 1.0
+	if standard > 0 {
+		pay = float64(paid) / standard
+	}
+
+	// morale moves towards the target
+	target := int(100*(pay-1) + 100*(fed-1))
+	if target < -100 {
+		target = -100
+	} else if target > 100 {
+		target = 100
+	}
+	s.Morale += (target - s.Morale) / 2
+
+	// births are unskilled workers, and hunger slows them down
+	hungry := 1 - fed
+	if hungry < 0 {
+		hungry = 0
+	}
+	births := int(float64(pop) * birthRate * (1 - hungry))
+	dying := deathRate + starvationRate*hungry
+	for _, code := range []string{"CIV", "CONS", "PRO", "SLD", "SPY", "UNSK"} {
+		if deaths := int(float64(s.Population[code]) * dying); deaths > 0 {
+			if deaths > s.Population[code] {
+				deaths = s.Population[code]
+			}
+			s.AddPopulation(code, -deaths)
+		}
+	}
+	s.AddPopulation("UNSK", births)
+	return fed, pay
+}
+
+// named returns only the units with the given name.
+func named(m map[units.Unit]int, name string) map[units.Unit]int {
+	list := make(map[units.Unit]int)
+	for unit, qty := range m {
+		if unit.Name == name {
+			list[unit] = qty
+		}
+	}
+	return list
+}
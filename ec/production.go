@@ -0,0 +1,344 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package ec
+
+import (
+	"fmt"
+	"github.com/mdhender/wraithh/models/coordinates"
+	"github.com/mdhender/wraithh/models/ships"
+	"github.com/mdhender/wraithh/models/units"
+	"strconv"
+	"strings"
+)
+
+const (
+	// mineOutput is the quantity of resources that a single MINE
+	// extracts each turn, per tech level.
+	mineOutput = 100
+	// factoryOutput is the number of products that a single FACT
+	// manufactures each turn, per tech level.
+	factoryOutput = 20
+	// productMetallics and productNonMetallics are the resources consumed
+	// to manufacture one unit of product, per tech level of the product.
+	productMetallics    = 1
+	productNonMetallics = 1
+)
+
+// ProductionPhase assembles, expands and retools factory and mine groups,
+// and assembles, recycles, scraps and stores units.
+func (e *Engine) ProductionPhase(orders *Orders) error {
+	for _, order := range orders.Orders {
+		var line int
+		var err error
+		switch o := order.(type) {
+		case *AssembleFactoryGroup:
+			line, err = o.Line, e.assembleFactoryGroup(orders.Nation, o)
+		case *AssembleMineGroup:
+			line, err = o.Line, e.assembleMineGroup(orders.Nation, o)
+		case *AssembleUnit:
+			line, err = o.Line, e.assembleUnit(orders.Nation, o)
+		case *ExpandFactoryGroup:
+			line, err = o.Line, e.expandFactoryGroup(orders.Nation, o)
+		case *ExpandMineGroup:
+			line, err = o.Line, e.expandMineGroup(orders.Nation, o)
+		case *RetoolFactoryGroup:
+			line, err = o.Line, e.retoolFactoryGroup(orders.Nation, o)
+		case *RecycleUnit:
+			line, err = o.Line, e.removeUnit(orders.Nation, o.Id, o.Unit, o.Quantity, recycle)
+		case *RecycleFactoryGroup:
+			line, err = o.Line, e.removeFactories(orders.Nation, o.Id, o.FactoryGroup, o.Unit, o.Quantity, recycle)
+		case *RecycleMineGroup:
+			line, err = o.Line, e.removeMines(orders.Nation, o.Id, o.MineGroup, o.Unit, o.Quantity, recycle)
+		case *ScrapUnit:
+			line, err = o.Line, e.removeUnit(orders.Nation, o.Id, o.Unit, o.Quantity, scrap)
+		case *ScrapFactoryGroup:
+			line, err = o.Line, e.removeFactories(orders.Nation, o.Id, o.FactoryGroup, o.Unit, o.Quantity, scrap)
+		case *ScrapMineGroup:
+			line, err = o.Line, e.removeMines(orders.Nation, o.Id, o.MineGroup, o.Unit, o.Quantity, scrap)
+		case *StoreUnit:
+			line, err = o.Line, e.storeUnit(orders.Nation, o)
+		case *StoreFactoryGroup:
+			line, err = o.Line, e.removeFactories(orders.Nation, o.Id, o.FactoryGroup, o.Unit, o.Quantity, store)
+		case *StoreMineGroup:
+			line, err = o.Line, e.removeMines(orders.Nation, o.Id, o.MineGroup, o.Unit, o.Quantity, store)
+		default:
+			continue
+		}
+		e.result(orders, line, order, err)
+	}
+	return nil
+}
+
+// productionPhase runs every player's production orders, then lets
+// every mine group and factory group in the game produce.
+func productionPhase(e *Engine, orders []*Orders) error {
+	for _, po := range orders {
+		if err := e.ProductionPhase(po); err != nil {
+			return err
+		}
+	}
+	e.produce()
+	e.research()
+	return nil
+}
+
+func (e *Engine) assembleFactoryGroup(nation string, o *AssembleFactoryGroup) error {
+	unit, err := e.ownedUnit(nation, o.Id)
+	if err != nil {
+		return err
+	} else if o.Unit.Name != "FACT" {
+		return fmt.Errorf("%s is not a factory", o.Unit)
+	} else if err = e.canBuild(nation, o.Unit); err != nil {
+		return err
+	} else if !canManufacture(o.Manufacture) {
+		return fmt.Errorf("%s is not a product", o.Manufacture)
+	} else if err = e.canBuild(nation, o.Manufacture); err != nil {
+		return err
+	} else if err = unit.Take(o.Unit, o.Quantity); err != nil {
+		return err
+	}
+	unit.FactoryGroups = append(unit.FactoryGroups, &ships.FactoryGroup{
+		Id:          nextGroupId("FG", len(unit.FactoryGroups), func(i int) string { return unit.FactoryGroups[i].Id }),
+		Factories:   map[units.Unit]int{o.Unit: o.Quantity},
+		Manufacture: o.Manufacture,
+	})
+	return nil
+}
+
+func (e *Engine) assembleMineGroup(nation string, o *AssembleMineGroup) error {
+	unit, err := e.ownedUnit(nation, o.Id)
+	if err != nil {
+		return err
+	} else if o.Unit.Name != "MINE" {
+		return fmt.Errorf("%s is not a mine", o.Unit)
+	} else if err = e.canBuild(nation, o.Unit); err != nil {
+		return err
+	}
+	if err = e.canMine(unit, o.DepositId); err != nil {
+		return err
+	} else if err = unit.Take(o.Unit, o.Quantity); err != nil {
+		return err
+	}
+	unit.MineGroups = append(unit.MineGroups, &ships.MineGroup{
+		Id:        nextGroupId("MG", len(unit.MineGroups), func(i int) string { return unit.MineGroups[i].Id }),
+		DepositId: o.DepositId,
+		Mines:     map[units.Unit]int{o.Unit: o.Quantity},
+	})
+	return nil
+}
+
+func (e *Engine) expandFactoryGroup(nation string, o *ExpandFactoryGroup) error {
+	unit, err := e.ownedUnit(nation, o.Id)
+	if err != nil {
+		return err
+	} else if o.Unit.Name != "FACT" {
+		return fmt.Errorf("%s is not a factory", o.Unit)
+	} else if err = e.canBuild(nation, o.Unit); err != nil {
+		return err
+	}
+	fg := factoryGroup(unit, o.FactoryGroup)
+	if fg == nil {
+		return fmt.Errorf("factory group %s does not exist", o.FactoryGroup)
+	} else if err = unit.Take(o.Unit, o.Quantity); err != nil {
+		return err
+	}
+	if fg.Factories == nil {
+		fg.Factories = make(map[units.Unit]int)
+	}
+	fg.Factories[o.Unit] += o.Quantity
+	return nil
+}
+
+func (e *Engine) expandMineGroup(nation string, o *ExpandMineGroup) error {
+	unit, err := e.ownedUnit(nation, o.Id)
+	if err != nil {
+		return err
+	} else if o.Unit.Name != "MINE" {
+		return fmt.Errorf("%s is not a mine", o.Unit)
+	} else if err = e.canBuild(nation, o.Unit); err != nil {
+		return err
+	}
+	mg := mineGroup(unit, o.MineGroup)
+	if mg == nil {
+		return fmt.Errorf("mine group %s does not exist", o.MineGroup)
+	} else if err = unit.Take(o.Unit, o.Quantity); err != nil {
+		return err
+	}
+	if mg.Mines == nil {
+		mg.Mines = make(map[units.Unit]int)
+	}
+	mg.Mines[o.Unit] += o.Quantity
+	return nil
+}
+
+func (e *Engine) retoolFactoryGroup(nation string, o *RetoolFactoryGroup) error {
+	unit, err := e.ownedUnit(nation, o.Id)
+	if err != nil {
+		return err
+	} else if !canManufacture(o.Unit) {
+		return fmt.Errorf("%s is not a product", o.Unit)
+	} else if err = e.canBuild(nation, o.Unit); err != nil {
+		return err
+	}
+	fg := factoryGroup(unit, o.FactoryGroup)
+	if fg == nil {
+		return fmt.Errorf("factory group %s does not exist", o.FactoryGroup)
+	} else if fg.Manufacture == o.Unit {
+		return fmt.Errorf("factory group %s already manufactures %s", o.FactoryGroup, o.Unit)
+	}
+	fg.Manufacture = o.Unit
+	return nil
+}
+
+// canMine returns an error if the unit can't mine the deposit.
+// The deposit must be in the same orbit as the unit, must not be
+// controlled by another nation, and must not already be mined by one
+// of the unit's mine groups.
+func (e *Engine) canMine(unit *ships.Ship, depositId string) error {
+	deposit, ok := e.Deposits[depositId]
+	if !ok {
+		return fmt.Errorf("deposit %s does not exist", depositId)
+	} else if deposit.ControlledBy != "" && deposit.ControlledBy != unit.Owner {
+		return fmt.Errorf("deposit %s is controlled by nation %s", depositId, deposit.ControlledBy)
+	}
+	orbit := e.orbitOf(depositId)
+	if orbit == nil || !sameOrbit(orbit.Location, unit.Location) {
+		return fmt.Errorf("deposit %s is not in orbit with unit %s", depositId, unit.Id)
+	}
+	for _, mg := range unit.MineGroups {
+		if mg.DepositId == depositId {
+			return fmt.Errorf("deposit %s is already mined by %s", depositId, mg.Id)
+		}
+	}
+	return nil
+}
+
+// produce runs every mine group and then every factory group in the game.
+// Units are processed in order of id so that results are repeatable.
+func (e *Engine) produce() {
+	var list []*ships.Ship
+	list = append(list, sortShips(e.Ships)...)
+	list = append(list, sortShips(e.Colonies)...)
+	for _, unit := range list {
+		for _, mg := range unit.MineGroups {
+			e.mine(unit, mg)
+		}
+	}
+	for _, unit := range list {
+		for _, fg := range unit.FactoryGroups {
+			manufacture(unit, fg)
+		}
+	}
+}
+
+// mine extracts resources from the group's deposit into the unit.
+// Output is scaled by the productivity of the unit's population.
+func (e *Engine) mine(unit *ships.Ship, mg *ships.MineGroup) {
+	deposit, ok := e.Deposits[mg.DepositId]
+	if !ok || deposit.QtyRemaining <= 0 {
+		return
+	} else if deposit.ControlledBy != "" && deposit.ControlledBy != unit.Owner {
+		// the mines stop when another nation takes the deposit
+		return
+	}
+	qty := int(float64(capacity(mg.Mines)*mineOutput) * unit.Productivity())
+	if qty > deposit.QtyRemaining {
+		qty = deposit.QtyRemaining
+	}
+	// the mines stop when the cargo space is full
+	if free := unit.FreeCapacity(); qty > free {
+		qty = free
+	}
+	if qty <= 0 {
+		return
+	}
+	deposit.QtyRemaining -= qty
+	unit.AddResource(deposit.Resource.String(), qty)
+}
+
+// manufacture turns the unit's resources into the group's product.
+// Higher tech level products take more capacity and more resources
+// to build. Output is scaled by the productivity of the unit's population
+// and limited by the resources and cargo space on hand.
+func manufacture(unit *ships.Ship, fg *ships.FactoryGroup) {
+	if !fg.Manufacture.IsProduct() {
+		// research is added to the nation, not to the unit
+		return
+	}
+	tl := fg.Manufacture.TechLevel
+	if tl < 1 {
+		tl = 1
+	}
+	qty := int(float64(capacity(fg.Factories)*factoryOutput)*unit.Productivity()) / tl
+	if n := unit.Resources["MTL"] / (productMetallics * tl); n < qty {
+		qty = n
+	}
+	if n := unit.Resources["NMTL"] / (productNonMetallics * tl); n < qty {
+		qty = n
+	}
+	// products may need more cargo space than the resources they use up
+	if grows := fg.Manufacture.Volume() - (productMetallics+productNonMetallics)*tl; grows > 0 {
+		if n := unit.FreeCapacity() / grows; n < qty {
+			qty = n
+		}
+	}
+	if qty <= 0 {
+		return
+	}
+	unit.AddResource("MTL", -qty*productMetallics*tl)
+	unit.AddResource("NMTL", -qty*productNonMetallics*tl)
+	unit.AddStored(fg.Manufacture, qty)
+}
+
+// canManufacture returns true if a factory group can be tooled for the unit.
+func canManufacture(unit units.Unit) bool {
+	return unit.IsProduct() || unit.Name == "RESEARCH"
+}
+
+// capacity returns the number of units in a group weighted by tech level.
+func capacity(group map[units.Unit]int) int {
+	var total int
+	for unit, qty := range group {
+		tl := unit.TechLevel
+		if tl < 1 {
+			tl = 1
+		}
+		total += qty * tl
+	}
+	return total
+}
+
+func factoryGroup(s *ships.Ship, id string) *ships.FactoryGroup {
+	for _, fg := range s.FactoryGroups {
+		if fg.Id == id {
+			return fg
+		}
+	}
+	return nil
+}
+
+func mineGroup(s *ships.Ship, id string) *ships.MineGroup {
+	for _, mg := range s.MineGroups {
+		if mg.Id == id {
+			return mg
+		}
+	}
+	return nil
+}
+
+// nextGroupId returns the next unused group id, "FG-n" or "MG-n".
+func nextGroupId(prefix string, n int, id func(i int) string) string {
+	var max int
+	for i := 0; i < n; i++ {
+		if no, err := strconv.Atoi(strings.TrimPrefix(id(i), prefix+"-")); err == nil && no > max {
+			max = no
+		}
+	}
+	return fmt.Sprintf("%s-%d", prefix, max+1)
+}
+
+// sameOrbit returns true if both locations are in the same orbit of the same star.
+func sameOrbit(a, b coordinates.Coordinates) bool {
+	return a.StarLocation() == b.StarLocation() && a.Orbit == b.Orbit
+}
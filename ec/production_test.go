@@ -0,0 +1,55 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package ec
+
+import (
+	"github.com/mdhender/wraithh/models/coordinates"
+	"github.com/mdhender/wraithh/models/units"
+	"strings"
+	"testing"
+)
+
+func TestAssembleUnit(t *testing.T) {
+	sdrv := units.Unit{Name: "SDRV", TechLevel: 1}
+	snsr := units.Unit{Name: "SNSR", TechLevel: 3}
+	fact := units.Unit{Name: "FACT", TechLevel: 1}
+	for _, tc := range []struct {
+		name    string
+		unit    units.Unit
+		qty     int
+		wantErr string // part of the expected error, empty for none
+	}{
+		{name: "install from storage", unit: sdrv, qty: 4},
+		{name: "above tech level", unit: snsr, qty: 1, wantErr: "above tech level 2"},
+		{name: "not installable", unit: fact, qty: 1, wantErr: "can't be installed"},
+		{name: "not enough in storage", unit: sdrv, qty: 11, wantErr: "in storage"},
+		{name: "zero quantity", unit: sdrv, qty: 0, wantErr: "quantity must be positive"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			e := testEngine("1")
+			e.Nations["1"].TechLevel = 2
+			s := testShip(e, 10, "1", coordinates.Coordinates{System: "A", Orbit: 1}, 0)
+			s.Population = nil
+			s.AddInstalled(units.Unit{Name: "SU", TechLevel: 1}, 100)
+			for _, unit := range []units.Unit{sdrv, snsr, fact} {
+				s.AddStored(unit, 10)
+			}
+
+			err := e.assembleUnit("1", &AssembleUnit{Line: 1, Id: 10, Quantity: tc.qty, Unit: tc.unit})
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("assemble: got %v, want %q", err, tc.wantErr)
+				} else if s.Stored[tc.unit] != 10 || s.Installed[tc.unit] != 0 {
+					t.Errorf("failed assemble changed the ship: %d stored, %d installed", s.Stored[tc.unit], s.Installed[tc.unit])
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("assemble: %v", err)
+			}
+			if s.Stored[tc.unit] != 10-tc.qty || s.Installed[tc.unit] != tc.qty {
+				t.Errorf("got %d stored, %d installed, want %d and %d", s.Stored[tc.unit], s.Installed[tc.unit], 10-tc.qty, tc.qty)
+			}
+		})
+	}
+}
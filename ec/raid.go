@@ -0,0 +1,78 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package ec
+
+import (
+	"fmt"
+	"github.com/mdhender/wraithh/models/ships"
+	"github.com/mdhender/wraithh/models/units"
+)
+
+// raidShare is the largest fraction of the target's stock that a
+// successful raid can carry off.
+const raidShare = 0.5
+
+// Plunder is the cargo that a raider took from its target.
+type Plunder struct {
+	RaiderId string
+	Unit     units.Unit
+	Quantity int
+}
+
+// raid validates a raid order and adds it to the battle for the target.
+// Raiders can take population, products in storage, resources or research.
+func (e *Engine) raid(po *Orders, o *Raid, engage func(*ships.Ship) *engagement) error {
+	unit := o.TargetUnit
+	if !(unit.IsPopulation() || unit.IsResource() || unit.IsProduct() || unit.Name == "RESEARCH") {
+		return fmt.Errorf("%s can't be raided", unit)
+	}
+	return e.attack(po, o, o.Id, o.PctCommitted, o.TargetId, engage)
+}
+
+// raidOnly returns true if every attacker in the engagement is raiding.
+func (eg *engagement) raidOnly() bool {
+	for _, c := range eg.attackers {
+		if _, ok := c.order.(*Raid); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// plunder moves a share of the target's stock of the unit to the raider.
+// The share grows with the raider's part in the attack, and the raider
+// can't take more than fits in its free cargo space. Research is taken
+// from the target's nation and given to the raider's nation.
+// It returns nil if nothing was taken.
+func (e *Engine) plunder(c *combatant, target *ships.Ship, unit units.Unit, share float64) *Plunder {
+	if unit.Name == "RESEARCH" {
+		from, ok := e.Nations[target.Owner]
+		if !ok {
+			return nil
+		}
+		to, ok := e.Nations[c.nation]
+		if !ok {
+			return nil
+		}
+		qty := int(float64(from.Research) * raidShare * share)
+		if qty < 1 {
+			return nil
+		}
+		from.Research -= qty
+		to.Research += qty
+		return &Plunder{RaiderId: c.unit.Id, Unit: unit, Quantity: qty}
+	}
+
+	qty := int(float64(target.Quantity(unit)) * raidShare * share)
+	if volume := unit.Volume(); volume > 0 {
+		if room := c.unit.FreeCapacity() / volume; qty > room {
+			qty = room
+		}
+	}
+	if qty < 1 || target.Take(unit, qty) != nil {
+		return nil
+	}
+	c.unit.Add(unit, qty)
+	return &Plunder{RaiderId: c.unit.Id, Unit: unit, Quantity: qty}
+}
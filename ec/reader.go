@@ -4,13 +4,22 @@
 package ec
 
 import (
+	"errors"
+	"fmt"
+	"github.com/mdhender/wraithh/models/cluster"
+	"github.com/mdhender/wraithh/models/knowledge"
+	"github.com/mdhender/wraithh/models/nations"
 	"github.com/mdhender/wraithh/models/player"
+	"github.com/mdhender/wraithh/models/ships"
+	"github.com/mdhender/wraithh/models/systems"
+	"io/fs"
 )
 
 type GameJS struct {
 	Id      string   `json:"id,omitempty"`
 	Name    string   `json:"name,omitempty"`
 	Turn    int      `json:"turn,omitempty"`
+	Seed    int64    `json:"seed,omitempty"`
 	Players []string `json:"players,omitempty"`
 }
 
@@ -20,9 +29,11 @@ type PlayerJS struct {
 	Nation string `json:"nation,omitempty"`
 }
 
+// LoadGame reads the state of the game from the directory.
 func LoadGame(path string) (*Engine, error) {
 	var e Engine
 	e.Players = make(map[string]player.Player)
+	e.Phases = DefaultPhases()
 
 	var game GameJS
 	if err := fromjson(path, "game", &game); err != nil {
@@ -31,19 +42,102 @@ func LoadGame(path string) (*Engine, error) {
 	e.Game.Id = game.Id
 	e.Game.Name = game.Name
 	e.Game.Turn = game.Turn
+	e.Game.Seed = game.Seed
 
 	players := make(map[string]PlayerJS)
 	if err := fromjson(path, "players", &players); err != nil {
 		return nil, err
 	}
 	for k, p := range players {
+		// secrets are stored as salted hashes, so hash any plaintext secret
+		// that was added to the file by hand.
+		secret := p.Secret
+		if !player.IsHashed(secret) {
+			var err error
+			if secret, err = player.HashSecret(secret); err != nil {
+				return nil, fmt.Errorf("player %s: %w", p.Handle, err)
+			}
+		}
 		e.Players[k] = player.Player{
 			Id:     k,
 			Handle: p.Handle,
-			Secret: p.Secret,
+			Secret: secret,
 			Nation: p.Nation,
 		}
 	}
 
+	// nations are created at tech level 1 the first time they are loaded
+	var na []*nations.Nation
+	if err := fromjson(path, "nations", &na); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	e.Nations = make(map[string]*nations.Nation)
+	for _, n := range na {
+		e.Nations[n.Id] = n
+	}
+	for _, p := range e.Players {
+		if _, ok := e.Nations[p.Nation]; !ok && p.Nation != "" {
+			e.Nations[p.Nation] = &nations.Nation{Id: p.Nation, TechLevel: 1}
+		}
+	}
+
+	// nations start out knowing nothing
+	var kn []*knowledge.Knowledge
+	if err := fromjson(path, "knowledge", &kn); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	e.Knowledge = make(map[string]*knowledge.Knowledge)
+	for _, k := range kn {
+		e.Knowledge[k.Nation] = k
+	}
+	for id := range e.Nations {
+		if _, ok := e.Knowledge[id]; !ok {
+			e.Knowledge[id] = knowledge.New(id)
+		}
+	}
+
+	e.Cluster = &cluster.Cluster{}
+	if err := fromjson(path, "cluster", e.Cluster); err != nil {
+		return nil, err
+	}
+
+	var sy []*systems.System
+	if err := fromjson(path, "systems", &sy); err != nil {
+		return nil, err
+	}
+	e.Systems = make(map[string]*systems.System)
+	for _, s := range sy {
+		e.Systems[s.Id] = s
+	}
+
+	var st []*systems.Star
+	if err := fromjson(path, "stars", &st); err != nil {
+		return nil, err
+	}
+	e.Stars = make(map[string]*systems.Star)
+	for _, s := range st {
+		e.Stars[s.Id] = s
+	}
+	e.indexOrbits()
+
+	// a new game may not have any ships or colonies yet
+	var sh []*ships.Ship
+	if err := fromjson(path, "ships", &sh); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	e.Ships = make(map[string]*ships.Ship)
+	for _, s := range sh {
+		e.Ships[s.Id] = s
+	}
+
+	var co []*ships.Ship
+	if err := fromjson(path, "colonies", &co); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	e.Colonies = make(map[string]*ships.Ship)
+	for _, c := range co {
+		e.Colonies[c.Id] = c
+	}
+
 	return &e, nil
 }
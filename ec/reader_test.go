@@ -0,0 +1,108 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package ec
+
+import (
+	"github.com/mdhender/wraithh/models/orbits"
+	"github.com/mdhender/wraithh/models/units"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadSaveRoundTrip(t *testing.T) {
+	path := t.TempDir()
+	for name, data := range map[string]string{
+		"game":    `{"id": "G1", "name": "test", "turn": 3, "seed": 42}`,
+		"players": `{"1": {"handle": "alice", "secret": "swordfish", "nation": "1"}}`,
+		"cluster": `{"Radius": 15, "Systems": ["(0 0 0)"], "Stars": ["(0 0 0A)"]}`,
+		"systems": `[{"Id": "(0 0 0)", "Location": "(0 0 0)", "Stars": ["(0 0 0A)"]}]`,
+		"stars": `[{"Id": "(0 0 0A)", "Location": "(0 0 0A)", "Orbits": [
+			{},
+			{"Id": "(0 0 0A 1)", "Location": "(0 0 0A 1)", "Kind": "terrestrial", "Habitability": 12, "ControlledBy": "1",
+			 "Colonies": {"Open": ["11"]},
+			 "Deposits": [{"Id": "DP-1", "Resource": "MTL", "ControlledBy": "1", "QtyInitial": 1000, "QtyRemaining": 900}],
+			 "Grants": {"TRADE": ["2"]}},
+			{"Id": "(0 0 0A 2)", "Location": "(0 0 0A 2)", "Kind": "gas-giant"}
+		]}]`,
+		"ships": `[{"Id": "10", "Owner": "1", "Kind": "ship", "Location": "(0 0 0A 1)",
+			"Installed": {"SU": 100, "HDRV-1": 2},
+			"Stored": {"SNSR-2": 1},
+			"Resources": {"FUEL": 50, "GOLD": 7}}]`,
+		"colonies": `[{"Id": "11", "Owner": "1", "Kind": "open", "Location": "(0 0 0A 1)",
+			"Installed": {"SU": 1000},
+			"Population": {"UNSK": 100, "PRO": 10, "SLD": 5},
+			"FactoryGroups": [{"Id": "FG-1", "Factories": {"FACT-1": 20}, "Manufacture": "CNGD"}],
+			"MineGroups": [{"Id": "MG-1", "DepositId": "DP-1", "Mines": {"MINE-1": 10}}],
+			"Morale": -5, "Unrest": 3, "Rebels": 2}]`,
+	} {
+		if err := os.WriteFile(filepath.Join(path, name+".json"), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	first, err := LoadGame(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if err := first.SaveGame(path); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	second, err := LoadGame(path)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+
+	if !reflect.DeepEqual(first.Game, second.Game) {
+		t.Errorf("game: got %+v, want %+v", second.Game, first.Game)
+	}
+	if !reflect.DeepEqual(first.Players, second.Players) {
+		t.Errorf("players: got %+v, want %+v", second.Players, first.Players)
+	}
+	if !reflect.DeepEqual(first.Nations, second.Nations) {
+		t.Errorf("nations: got %+v, want %+v", second.Nations, first.Nations)
+	}
+	if !reflect.DeepEqual(first.Knowledge, second.Knowledge) {
+		t.Errorf("knowledge: got %+v, want %+v", second.Knowledge, first.Knowledge)
+	}
+	if !reflect.DeepEqual(first.Cluster, second.Cluster) {
+		t.Errorf("cluster: got %+v, want %+v", second.Cluster, first.Cluster)
+	}
+	if !reflect.DeepEqual(first.Systems, second.Systems) {
+		t.Errorf("systems: got %+v, want %+v", second.Systems, first.Systems)
+	}
+	if !reflect.DeepEqual(first.Stars, second.Stars) {
+		t.Errorf("stars: got %+v, want %+v", second.Stars, first.Stars)
+	}
+	if !reflect.DeepEqual(first.Orbits, second.Orbits) {
+		t.Errorf("orbits: got %+v, want %+v", second.Orbits, first.Orbits)
+	}
+	if !reflect.DeepEqual(first.Deposits, second.Deposits) {
+		t.Errorf("deposits: got %+v, want %+v", second.Deposits, first.Deposits)
+	}
+	if !reflect.DeepEqual(first.Ships, second.Ships) {
+		t.Errorf("ships: got %+v, want %+v", second.Ships, first.Ships)
+	}
+	if !reflect.DeepEqual(first.Colonies, second.Colonies) {
+		t.Errorf("colonies: got %+v, want %+v", second.Colonies, first.Colonies)
+	}
+
+	// make sure the fixture was read, so the comparisons above mean something
+	if o := second.Orbits["(0 0 0A 1)"]; o == nil || o.ControlledBy != "1" || !reflect.DeepEqual(o.Grants["TRADE"], []string{"2"}) {
+		t.Errorf("orbit: got %+v", o)
+	}
+	if d := second.Deposits["DP-1"]; d == nil || d.Resource != orbits.Metallics || d.QtyRemaining != 900 {
+		t.Errorf("deposit: got %+v", d)
+	}
+	if s := second.Ships["10"]; s == nil || s.Installed[units.Unit{Name: "HDRV", TechLevel: 1}] != 2 || s.Stored[units.Unit{Name: "SNSR", TechLevel: 2}] != 1 || s.Resources["FUEL"] != 50 {
+		t.Errorf("ship: got %+v", s)
+	}
+	if c := second.Colonies["11"]; c == nil || c.Population["UNSK"] != 100 || len(c.FactoryGroups) != 1 || len(c.MineGroups) != 1 || c.MineGroups[0].DepositId != "DP-1" {
+		t.Errorf("colony: got %+v", c)
+	}
+	if !second.Players["1"].VerifySecret("swordfish") {
+		t.Errorf("secret: hashed secret does not verify after reload")
+	}
+}
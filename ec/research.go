@@ -0,0 +1,81 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package ec
+
+import (
+	"fmt"
+	"github.com/mdhender/wraithh/models/nations"
+	"github.com/mdhender/wraithh/models/ships"
+	"github.com/mdhender/wraithh/models/units"
+	"sort"
+)
+
+const (
+	// professionalsPerPoint is the number of professionals that
+	// produce one research point each turn.
+	professionalsPerPoint = 100
+	// factoryResearch is the research points that a single FACT
+	// produces each turn, per tech level, when it is tooled for RESEARCH.
+	factoryResearch = 2
+)
+
+// research adds the research produced by every ship and colony to its
+// nation, then advances every nation that has enough points.
+func (e *Engine) research() {
+	for _, list := range []map[string]*ships.Ship{e.Ships, e.Colonies} {
+		for _, s := range sortShips(list) {
+			n, ok := e.Nations[s.Owner]
+			if !ok {
+				continue
+			}
+			points := s.Population["PRO"] / professionalsPerPoint
+			for _, fg := range s.FactoryGroups {
+				if fg.Manufacture.Name == "RESEARCH" {
+					points += capacity(fg.Factories) * factoryResearch
+				}
+			}
+			n.Research += int(float64(points) * s.Productivity())
+		}
+	}
+	for _, id := range sortedNations(e.Nations) {
+		n := e.Nations[id]
+		for n.TechLevel < nations.MaxTechLevel && n.Research >= nations.ResearchCost(n.TechLevel+1) {
+			n.Research -= nations.ResearchCost(n.TechLevel + 1)
+			n.TechLevel++
+		}
+	}
+}
+
+// techLevel returns the nation's current tech level.
+func (e *Engine) techLevel(nation string) int {
+	if n, ok := e.Nations[nation]; ok {
+		return n.TechLevel
+	}
+	return 1
+}
+
+// canBuild returns an error if the nation's tech level is too low
+// to build the unit.
+func (e *Engine) canBuild(nation string, unit units.Unit) error {
+	if tl := e.techLevel(nation); unit.TechLevel > tl {
+		return fmt.Errorf("%s is above tech level %d", unit, tl)
+	}
+	return nil
+}
+
+// isKnowledge returns true for research points and tech levels.
+// They belong to the nation rather than to a ship or colony.
+func isKnowledge(unit units.Unit) bool {
+	return unit.Name == "RESEARCH" || unit.Name == "TL"
+}
+
+// sortedNations returns the ids of the nations in sorted order.
+func sortedNations(m map[string]*nations.Nation) []string {
+	var ids []string
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,206 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package ec
+
+import (
+	"fmt"
+	"github.com/mdhender/wraithh/models/orbits"
+	"github.com/mdhender/wraithh/models/ships"
+	"github.com/mdhender/wraithh/models/units"
+	"strconv"
+)
+
+// SetupPhase creates new ships and colonies from cargo on existing units
+// and moves cargo between units.
+func (e *Engine) SetupPhase(orders *Orders) error {
+	for _, order := range orders.Orders {
+		var line int
+		var err error
+		switch o := order.(type) {
+		case *Setup:
+			line, err = o.Line, e.setup(orders.Nation, o)
+		case *Transfer:
+			line, err = o.Line, e.transfer(orders.Nation, o)
+		default:
+			continue
+		}
+		e.result(orders, line, order, err)
+	}
+	return nil
+}
+
+// setup creates a new ship or colony at the location and moves the items
+// from the source unit into it. Hulls, engines and other equipment are
+// installed on the new unit; everything else is loaded as cargo.
+//
+// Colonies can't be set up in an orbit controlled by another nation
+// unless it has granted the right to colonize.
+// A colony set up with life support is enclosed, or orbital if the orbit
+// is a gas giant. A colony without life support is open to the air and
+// needs a habitable terrestrial world.
+func (e *Engine) setup(nation string, o *Setup) error {
+	source, err := e.ownedUnit(nation, o.Id)
+	if err != nil {
+		return err
+	} else if source.Location.SystemLocation() != o.Location.SystemLocation() {
+		return fmt.Errorf("unit %d is not in system %s", o.Id, o.Location.SystemLocation())
+	} else if o.Action != "TRANSFER" {
+		return fmt.Errorf("unknown action %q", o.Action)
+	} else if len(o.Items) == 0 {
+		return fmt.Errorf("nothing to transfer")
+	}
+	orbit, err := e.orbitAt(o.Location)
+	if err != nil {
+		return err
+	}
+
+	// the source must have everything before anything is moved
+	want := make(map[units.Unit]int)
+	var lifeSupport bool
+	for _, item := range o.Items {
+		if item.Quantity < 1 {
+			return fmt.Errorf("quantity must be positive")
+		}
+		want[item.Unit] += item.Quantity
+		lifeSupport = lifeSupport || item.Unit.Name == "LS"
+	}
+	for unit, qty := range want {
+		if have := source.Quantity(unit); have < qty {
+			return fmt.Errorf("unit %d has %d %s, needs %d", o.Id, have, unit, qty)
+		}
+	}
+
+	s := &ships.Ship{Owner: nation, Location: orbit.Location}
+	switch o.Kind {
+	case "SHIP":
+		s.Kind = ships.Vessel
+	case "COLONY":
+		if err = e.canUseOrbit(nation, orbit, "COLONIZE"); err != nil {
+			return err
+		} else if s.Kind, err = colonyKind(orbit, lifeSupport); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown kind %q", o.Kind)
+	}
+	for unit, qty := range want {
+		if installs(unit) {
+			s.AddInstalled(unit, qty)
+		} else {
+			s.Add(unit, qty)
+		}
+	}
+	if s.Capacity() == 0 {
+		return fmt.Errorf("%s needs structural units", o.Kind)
+	} else if s.Volume() > s.Capacity() {
+		return fmt.Errorf("%s needs %d cargo space, has %d", o.Kind, s.Volume(), s.Capacity())
+	}
+
+	// taken holds what has been moved, so it can be put back on failure
+	taken := make(map[units.Unit]int)
+	for unit, qty := range want {
+		if err := source.Take(unit, qty); err != nil {
+			for unit, qty := range taken {
+				source.Add(unit, qty)
+			}
+			return err
+		}
+		taken[unit] = qty
+	}
+	s.Id = e.nextUnitId()
+	if s.Kind == ships.Vessel {
+		e.Ships[s.Id] = s
+		return nil
+	}
+	e.Colonies[s.Id] = s
+	switch s.Kind {
+	case ships.OpenColony:
+		orbit.Colonies.Open = append(orbit.Colonies.Open, s.Id)
+	case ships.EnclosedColony:
+		orbit.Colonies.Closed = append(orbit.Colonies.Closed, s.Id)
+	case ships.OrbitalColony:
+		orbit.Colonies.Orbital = append(orbit.Colonies.Orbital, s.Id)
+	}
+	return nil
+}
+
+// transfer moves cargo from one unit to another in the same orbit.
+// Moving cargo to another nation's unit is trade, and needs the right
+// to trade in the orbit. Deep space is open to everyone.
+func (e *Engine) transfer(nation string, o *Transfer) error {
+	source, err := e.ownedUnit(nation, o.Id)
+	if err != nil {
+		return err
+	}
+	target, ok := e.unit(o.TargetId)
+	if !ok || !sameOrbit(source.Location, target.Location) {
+		return fmt.Errorf("unit %d is not in orbit with unit %d", o.TargetId, o.Id)
+	} else if o.TargetId == o.Id {
+		return fmt.Errorf("unit %d can't transfer to itself", o.Id)
+	} else if isKnowledge(o.Unit) {
+		return fmt.Errorf("%s can't be transferred", o.Unit)
+	}
+	if target.Owner != nation {
+		if err := e.canTrade(nation, source); err != nil {
+			return err
+		}
+	}
+	if err := source.Take(o.Unit, o.Quantity); err != nil {
+		return err
+	} else if err := target.Put(o.Unit, o.Quantity); err != nil {
+		source.Add(o.Unit, o.Quantity)
+		return err
+	}
+	return nil
+}
+
+// colonyKind returns the kind of colony that can be set up in the orbit.
+func colonyKind(orbit *orbits.Orbit, lifeSupport bool) (ships.Kind, error) {
+	switch orbit.Kind {
+	case orbits.Terrestrial:
+		if lifeSupport {
+			return ships.EnclosedColony, nil
+		} else if orbit.Habitability == 0 {
+			return 0, fmt.Errorf("orbit %s is not habitable, an open colony needs life support", orbit.Id)
+		}
+		return ships.OpenColony, nil
+	case orbits.AsteroidBelt:
+		if !lifeSupport {
+			return 0, fmt.Errorf("orbit %s is an asteroid belt, a colony needs life support", orbit.Id)
+		}
+		return ships.EnclosedColony, nil
+	case orbits.GasGiant:
+		if !lifeSupport {
+			return 0, fmt.Errorf("orbit %s is a gas giant, a colony needs life support", orbit.Id)
+		}
+		return ships.OrbitalColony, nil
+	}
+	return 0, fmt.Errorf("orbit %s is empty and can't support a colony", orbit.Id)
+}
+
+// installs returns true if the unit is installed on a new ship or colony
+// rather than being loaded as cargo.
+func installs(unit units.Unit) bool {
+	if unit.IsStructural() {
+		return true
+	}
+	switch unit.Name {
+	case "ESHD", "FARM", "HDRV", "LS", "SDRV", "SNSR":
+		return true
+	}
+	return false
+}
+
+// nextUnitId returns an id that is not used by any ship or colony.
+func (e *Engine) nextUnitId() string {
+	var max int
+	for _, m := range []map[string]*ships.Ship{e.Ships, e.Colonies} {
+		for id := range m {
+			if n, err := strconv.Atoi(id); err == nil && n > max {
+				max = n
+			}
+		}
+	}
+	return strconv.Itoa(max + 1)
+}
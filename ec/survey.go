@@ -0,0 +1,229 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package ec
+
+import (
+	"fmt"
+	"github.com/mdhender/wraithh/models/coordinates"
+	"github.com/mdhender/wraithh/models/knowledge"
+	"github.com/mdhender/wraithh/models/ships"
+)
+
+// sensorRange is the distance, per tech level, that a unit's sensors
+// can probe another system.
+const sensorRange = 3
+
+// SurveyPhase surveys orbits and probes for ships and colonies.
+// What the unit learns is added to its nation's knowledge.
+func (e *Engine) SurveyPhase(orders *Orders) error {
+	for _, order := range orders.Orders {
+		var line int
+		var err error
+		switch o := order.(type) {
+		case *Survey:
+			line, err = o.Line, e.survey(orders.Nation, o.Id, o.Orbit)
+		case *SurveySystem:
+			line, err = o.Line, e.surveySystem(orders.Nation, o)
+		case *Probe:
+			line, err = o.Line, e.probe(orders.Nation, o.Id, o.Orbit)
+		case *ProbeSystem:
+			line, err = o.Line, e.probeSystem(orders.Nation, o)
+		default:
+			continue
+		}
+		e.result(orders, line, order, err)
+	}
+	return nil
+}
+
+// observePhase adds what every ship and colony can see from where it
+// is to its nation's knowledge: its system, the stars and orbits in the
+// system, and the other ships and colonies there that its sensors can
+// detect. It runs for every nation, whether or not it sent in orders.
+func observePhase(e *Engine, orders []*Orders) error {
+	for _, m := range []map[string]*ships.Ship{e.Ships, e.Colonies} {
+		for _, s := range sortShips(m) {
+			if _, ok := e.Nations[s.Owner]; ok {
+				e.observe(s)
+			}
+		}
+	}
+	return nil
+}
+
+// observe adds what the unit can see to its nation's knowledge.
+// Like a probe, it doesn't see units owned by a nation with a higher
+// tech level than its sensors, or its own nation's tech level if that
+// is better.
+func (e *Engine) observe(unit *ships.Ship) {
+	tl := sensors(unit)
+	if own := e.techLevel(unit.Owner); own > tl {
+		tl = own
+	}
+	k := e.knowledgeOf(unit.Owner)
+	sys, ok := e.Systems[unit.Location.SystemLocation().String()]
+	if !ok {
+		return
+	}
+	k.SeeSystem(sys, e.Game.Turn)
+	for _, id := range sys.Stars {
+		if star, ok := e.Stars[id]; ok {
+			k.SeeStar(star, e.Game.Turn)
+		}
+	}
+	for _, m := range []map[string]*ships.Ship{e.Ships, e.Colonies} {
+		for _, s := range sortShips(m) {
+			if s.Owner == unit.Owner || s.Location.SystemLocation() != sys.Location.SystemLocation() || e.techLevel(s.Owner) > tl {
+				continue
+			}
+			k.Sight(s, knowledge.Seen, e.Game.Turn)
+		}
+	}
+}
+
+// knowledgeOf returns the nation's knowledge, creating it if needed.
+func (e *Engine) knowledgeOf(nation string) *knowledge.Knowledge {
+	k, ok := e.Knowledge[nation]
+	if !ok {
+		k = knowledge.New(nation)
+		e.Knowledge[nation] = k
+	}
+	return k
+}
+
+// survey reveals the kind, habitability and deposits of an orbit around
+// the unit's star. Orbit zero is the unit's own orbit.
+func (e *Engine) survey(nation string, id, orbitNo int) error {
+	unit, err := e.ownedUnit(nation, id)
+	if err != nil {
+		return err
+	}
+	location := unit.Location.StarLocation()
+	location.Orbit = orbitNo
+	if orbitNo == 0 {
+		location.Orbit = unit.Location.Orbit
+	}
+	orbit, err := e.orbitAt(location)
+	if err != nil {
+		return err
+	}
+	e.knowledgeOf(nation).Survey(orbit, e.Game.Turn)
+	return nil
+}
+
+// surveySystem surveys every orbit of a star, or of every star in the
+// system if the location doesn't name a star. The unit must be in the system.
+func (e *Engine) surveySystem(nation string, o *SurveySystem) error {
+	unit, err := e.ownedUnit(nation, o.Id)
+	if err != nil {
+		return err
+	} else if unit.Location.SystemLocation() != o.Location.SystemLocation() {
+		return fmt.Errorf("unit %d is not in system %s", o.Id, o.Location.SystemLocation())
+	}
+	stars, err := e.starsAt(o.Location)
+	if err != nil {
+		return err
+	}
+	k := e.knowledgeOf(nation)
+	for _, id := range stars {
+		star := e.Stars[id]
+		for i := 1; i < len(star.Orbits); i++ {
+			k.Survey(&star.Orbits[i], e.Game.Turn)
+		}
+	}
+	return nil
+}
+
+// probe reveals the ships and colonies in an orbit around the unit's
+// star. Orbit zero is the unit's own orbit.
+func (e *Engine) probe(nation string, id, orbitNo int) error {
+	unit, err := e.ownedUnit(nation, id)
+	if err != nil {
+		return err
+	}
+	tl := sensors(unit)
+	if tl == 0 {
+		return fmt.Errorf("unit %d has no sensors", id)
+	}
+	location := unit.Location.StarLocation()
+	location.Orbit = orbitNo
+	if orbitNo == 0 {
+		location.Orbit = unit.Location.Orbit
+	}
+	if _, err := e.orbitAt(location); err != nil {
+		return err
+	}
+	e.sight(nation, tl, func(s *ships.Ship) bool {
+		return sameOrbit(s.Location, location)
+	})
+	return nil
+}
+
+// probeSystem reveals the ships and colonies in a star, or in every star
+// in the system if the location doesn't name a star. The system must be
+// within range of the unit's sensors.
+func (e *Engine) probeSystem(nation string, o *ProbeSystem) error {
+	unit, err := e.ownedUnit(nation, o.Id)
+	if err != nil {
+		return err
+	}
+	tl := sensors(unit)
+	if tl == 0 {
+		return fmt.Errorf("unit %d has no sensors", o.Id)
+	} else if distance := unit.Location.DistanceTo(o.Location); distance > float64(tl*sensorRange) {
+		return fmt.Errorf("system %s is out of sensor range", o.Location.SystemLocation())
+	}
+	stars, err := e.starsAt(o.Location)
+	if err != nil {
+		return err
+	}
+	e.sight(nation, tl, func(s *ships.Ship) bool {
+		return contains(stars, s.Location.StarLocation().String())
+	})
+	return nil
+}
+
+// sight adds the ships and colonies that match to the nation's knowledge.
+// Units owned by a nation with a higher tech level than the sensors
+// are not detected.
+func (e *Engine) sight(nation string, tl int, match func(s *ships.Ship) bool) {
+	k := e.knowledgeOf(nation)
+	for _, m := range []map[string]*ships.Ship{e.Ships, e.Colonies} {
+		for _, s := range sortShips(m) {
+			if s.Owner == nation || !match(s) || e.techLevel(s.Owner) > tl {
+				continue
+			}
+			k.Sight(s, knowledge.Surveyed, e.Game.Turn)
+		}
+	}
+}
+
+// sensors returns the tech level of the best sensors installed on the
+// unit, or zero if it has none.
+func sensors(s *ships.Ship) int {
+	var best int
+	for unit, qty := range s.Installed {
+		if unit.Name == "SNSR" && qty > 0 && techLevelOf(unit) > best {
+			best = techLevelOf(unit)
+		}
+	}
+	return best
+}
+
+// starsAt returns the ids of the star at the location, or of every
+// star in the system if the location doesn't name a star.
+func (e *Engine) starsAt(location coordinates.Coordinates) ([]string, error) {
+	if location.System != "" {
+		star := location.StarLocation().String()
+		if _, ok := e.Stars[star]; !ok {
+			return nil, fmt.Errorf("star %s does not exist", star)
+		}
+		return []string{star}, nil
+	}
+	sys, ok := e.Systems[location.SystemLocation().String()]
+	if !ok {
+		return nil, fmt.Errorf("system %s does not exist", location.SystemLocation())
+	}
+	return sys.Stars, nil
+}
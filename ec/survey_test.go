@@ -0,0 +1,85 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package ec
+
+import (
+	"github.com/mdhender/wraithh/models/coordinates"
+	"github.com/mdhender/wraithh/models/knowledge"
+	"github.com/mdhender/wraithh/models/ships"
+	"github.com/mdhender/wraithh/models/units"
+	"testing"
+)
+
+func TestSightHidesHigherTechUnits(t *testing.T) {
+	orbit := coordinates.Coordinates{System: "A", Orbit: 2}
+	for _, tc := range []struct {
+		name      string
+		sensors   int  // tech level of the probing sensors
+		techLevel int  // tech level of the other nation
+		want      bool // true if the other nation's ship is seen
+	}{
+		{name: "lower tech", sensors: 3, techLevel: 2, want: true},
+		{name: "same tech", sensors: 3, techLevel: 3, want: true},
+		{name: "higher tech", sensors: 3, techLevel: 4, want: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			e := testEngine("1", "2")
+			e.Nations["2"].TechLevel = tc.techLevel
+			testShip(e, 10, "1", orbit, 0)
+			testShip(e, 20, "2", orbit, 0)
+
+			e.sight("1", tc.sensors, func(s *ships.Ship) bool {
+				return sameOrbit(s.Location, orbit)
+			})
+
+			k := e.knowledgeOf("1")
+			if _, ok := k.Units["10"]; ok {
+				t.Errorf("own ship was added to knowledge")
+			}
+			u, ok := k.Units["20"]
+			if ok != tc.want {
+				t.Fatalf("seen: got %v, want %v", ok, tc.want)
+			} else if ok && u.Detail != knowledge.Surveyed {
+				t.Errorf("detail: got %d, want %d", u.Detail, knowledge.Surveyed)
+			}
+		})
+	}
+}
+
+func TestObserveHidesHigherTechUnits(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		sensors int  // tech level of the sensors on nation 1's ship
+		want    bool // true if nation 3's ship is seen
+	}{
+		{name: "no sensors", sensors: 0, want: false},
+		{name: "lower tech sensors", sensors: 2, want: false},
+		{name: "same tech sensors", sensors: 3, want: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			e := testEngine("1", "2", "3")
+			e.Nations["3"].TechLevel = 3
+			ship := testShip(e, 10, "1", coordinates.Coordinates{System: "A", Orbit: 1}, 0)
+			if tc.sensors != 0 {
+				ship.AddInstalled(units.Unit{Name: "SNSR", TechLevel: tc.sensors}, 1)
+			}
+			testShip(e, 20, "2", coordinates.Coordinates{System: "A", Orbit: 2}, 0)
+			testShip(e, 30, "3", coordinates.Coordinates{System: "A", Orbit: 3}, 0)
+
+			testProcess(t, e, nil)
+
+			k := e.knowledgeOf("1")
+			if _, ok := k.Units["20"]; !ok {
+				t.Errorf("ship 20: was not seen")
+			}
+			if _, ok := k.Units["30"]; ok != tc.want {
+				t.Errorf("ship 30: seen %v, want %v", ok, tc.want)
+			}
+			// the higher tech nation sees everyone
+			if k := e.knowledgeOf("3"); len(k.Units) != 2 {
+				t.Errorf("nation 3: got %d units, want 2", len(k.Units))
+			}
+		})
+	}
+}
@@ -6,8 +6,9 @@ package ec
 import (
 	"fmt"
 	"github.com/mdhender/wraithh/models/orders"
-	"log"
+	"github.com/mdhender/wraithh/models/player"
 	"sort"
+	"strings"
 )
 
 func (e *Engine) AddOrders(orders []orders.Order) error {
@@ -31,30 +32,52 @@ func (e *Engine) AddOrders(orders []orders.Order) error {
 	return nil
 }
 
+// Process runs every phase of the turn, in order, against the orders.
+// When every phase has run, the game moves on to the next turn.
 func (e *Engine) Process() error {
-	// process secrets phase
-	for _, po := range e.Orders { // for each player orders
-		err := e.SecretsPhase(po)
-		if err != nil {
-			// any error with secrets means the order file should be skipped
-			po.Validated = false
+	// sort orders by handle for consistent processing; the sort is stable
+	// so a player's order sets stay in the order they were added
+	sort.SliceStable(e.Orders, func(i, j int) bool {
+		return strings.ToLower(e.Orders[i].Handle) < strings.ToLower(e.Orders[j].Handle)
+	})
+
+	for _, phase := range e.Phases {
+		var orders []*Orders
+		for _, po := range e.Orders {
+			// order sets rejected by an earlier phase are dropped
+			if po.Error == nil {
+				orders = append(orders, po)
+			}
 		}
-		if po.Validated {
-			log.Printf("secrets: validated %s\n", po.Handle)
-		} else if po.Error == nil {
-			log.Printf("secrets: failed    %s\n", po.Handle)
-		} else {
-			log.Printf("secrets: failed    %s %v\n", po.Handle, po.Error)
+		e.phase = phase.Name
+		err := phase.Run(e, orders)
+		e.phase = ""
+		if err != nil {
+			return fmt.Errorf("%s: %w", phase.Name, err)
 		}
 	}
 
-	// sort orders by handle for consistent processing in future phases
-	sort.Slice(e.Orders, func(i, j int) bool {
-		if !e.Orders[i].Validated {
-			return false
+	// every order in a validated set gets a result, even if no phase ran it
+	for _, po := range e.Orders {
+		if !po.Validated {
+			continue
 		}
-		return e.Orders[i].Handle < e.Orders[j].Handle
-	})
+		executed := make(map[orders.Order]bool)
+		for _, r := range po.Results {
+			executed[r.Order] = true
+		}
+		for _, order := range po.Orders {
+			if !executed[order] {
+				e.result(po, lineOf(order), order, errNotImplemented)
+			}
+		}
+		sort.SliceStable(po.Results, func(i, j int) bool {
+			return po.Results[i].Line < po.Results[j].Line
+		})
+	}
+
+	e.Processed = e.Game.Turn
+	e.Game.Turn++
 
 	return nil
 }
@@ -69,25 +92,30 @@ func (e *Engine) SecretsPhase(orders *Orders) error {
 	orders.Game = secret.Game
 	orders.Turn = secret.Turn
 
-	// todo: unbake the secrets!
-	switch secret.Token {
-	case "003d626a-27c9-4f92-80f3-880384f22d08":
-		if orders.Handle != "mdhender" {
-			orders.Error = fmt.Errorf("invalid secret")
-			return nil
-		}
-		if orders.Game != "G1" {
-			orders.Error = fmt.Errorf("invalid game")
-			return nil
-		}
-		if orders.Turn != 5 {
-			orders.Error = fmt.Errorf("invalid turn")
-			return nil
+	// find the player submitting the orders and verify their credentials
+	var pl player.Player
+	var found bool
+	for _, p := range e.Players {
+		if strings.EqualFold(p.Handle, orders.Handle) {
+			pl, found = p, true
+			break
 		}
-		orders.Validated = true
-	default:
+	}
+	if !found || !pl.VerifySecret(secret.Token) {
 		orders.Error = fmt.Errorf("invalid secret")
 		return nil
 	}
+	orders.Player = pl.Id
+	if !strings.EqualFold(orders.Game, e.Game.Id) {
+		orders.Error = fmt.Errorf("invalid game")
+		return nil
+	}
+	if orders.Turn != e.Game.Turn {
+		orders.Error = fmt.Errorf("invalid turn")
+		return nil
+	}
+	orders.Nation = pl.Nation
+	orders.Validated = true
+
 	return nil
 }
@@ -0,0 +1,135 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package ec
+
+import (
+	"github.com/mdhender/wraithh/models/ships"
+	"math"
+)
+
+const (
+	// unrestCalm is the unrest that a colony loses each turn on its own.
+	unrestCalm = 5
+	// unrestPerShortfall is the unrest added each turn by a colony that
+	// gets no food, or no pay, at all. Smaller shortfalls add less.
+	unrestPerShortfall = 20
+	// unrestPerCrowding is the unrest added each turn by a colony that
+	// has filled all of its cargo space.
+	unrestPerCrowding = 20
+	// unrestPerRebel is the unrest added each turn by a colony that is
+	// entirely rebels. Fewer rebels add less.
+	unrestPerRebel = 50
+	// overcrowded is the share of cargo space that a colony can fill
+	// before the population starts to feel crowded.
+	overcrowded = 0.8
+	// defectRate is the share of the population that joins the rebels
+	// each turn when unrest is at its highest.
+	defectRate = 0.02
+	// returnRate is the share of the rebels that return to work each
+	// turn once the unrest has died down.
+	returnRate = 0.1
+	// revoltThreshold is the share of rebels at which they fight the
+	// colony's soldiers.
+	revoltThreshold = 0.25
+	// independenceThreshold is the share of rebels at which the colony
+	// throws off its nation.
+	independenceThreshold = 0.5
+)
+
+// Revolt is a fight between a colony's rebels and its soldiers.
+type Revolt struct {
+	UnitId       string
+	Owner        string // nation that owned the colony when the revolt started
+	Rebels       int    // rebels at the start of the revolt
+	RebelsLost   int
+	SoldiersLost int
+	Independent  bool // the colony threw off its nation
+}
+
+// agitate updates the colony's unrest and rebels after the population
+// has been fed and paid.
+//
+// Unrest grows when the colony goes short of food or pay, when it is
+// overcrowded, and when it already has rebels, including those incited
+// by enemy spies. As unrest grows, people join the rebels; once it has
+// died down, the rebels drift back to work. A colony with enough rebels
+// has a revolt, and a colony with even more becomes independent.
+func (e *Engine) agitate(s *ships.Ship, fed, paid float64) {
+	if s.Kind == ships.Vessel || s.Owner == ships.Independent {
+		return
+	}
+
+	delta := float64(-unrestCalm)
+	if fed < 1 {
+		delta += unrestPerShortfall * (1 - fed)
+	}
+	if paid < 1 {
+		delta += unrestPerShortfall * (1 - paid)
+	}
+	if capacity := s.Capacity(); capacity > 0 {
+		if used := float64(s.Volume()) / float64(capacity); used > overcrowded {
+			delta += unrestPerCrowding * (used - overcrowded) / (1 - overcrowded)
+		}
+	}
+	delta += unrestPerRebel * s.RebelShare()
+	s.Unrest += int(math.Round(delta))
+	if s.Unrest < 0 {
+		s.Unrest = 0
+	} else if s.Unrest > 100 {
+		s.Unrest = 100
+	}
+
+	if s.Unrest > 0 {
+		recruitRebels(s, int(float64(s.TotalPopulation())*defectRate*float64(s.Unrest)/100))
+	} else if s.Rebels > 0 {
+		back := int(math.Ceil(float64(s.Rebels) * returnRate))
+		s.Rebels -= back
+		s.AddPopulation("UNSK", back)
+	}
+
+	share := s.RebelShare()
+	if share < revoltThreshold {
+		return
+	}
+	rv := &Revolt{UnitId: s.Id, Owner: s.Owner, Rebels: s.Rebels}
+	if share >= independenceThreshold {
+		// the rebels take over and go back to work for themselves
+		rv.Independent = true
+		s.Owner = ships.Independent
+		s.AddPopulation("UNSK", s.Rebels)
+		s.Rebels, s.Unrest = 0, 0
+		s.Pay, s.Rations, s.Morale = nil, 0, 0
+	} else {
+		// soldiers and rebels trade losses, and the soldiers hit harder
+		sld := s.Population["SLD"]
+		rv.RebelsLost, rv.SoldiersLost = sld/4, s.Rebels/8
+		if rv.RebelsLost > s.Rebels {
+			rv.RebelsLost = s.Rebels
+		}
+		if rv.SoldiersLost > sld {
+			rv.SoldiersLost = sld
+		}
+		s.Rebels -= rv.RebelsLost
+		s.AddPopulation("SLD", -rv.SoldiersLost)
+	}
+	e.Revolts = append(e.Revolts, rv)
+}
+
+// recruitRebels turns unskilled workers, then civilians, into rebels.
+// It returns the number of people who joined the rebels.
+func recruitRebels(s *ships.Ship, qty int) int {
+	var recruited int
+	for _, code := range []string{"UNSK", "CIV"} {
+		n := qty - recruited
+		if n > s.Population[code] {
+			n = s.Population[code]
+		}
+		if n > 0 {
+			s.AddPopulation(code, -n)
+			recruited += n
+		}
+	}
+	s.Rebels += recruited
+	return recruited
+}
@@ -4,19 +4,26 @@
 package ec
 
 import (
-	"path/filepath"
+	"github.com/mdhender/wraithh/models/knowledge"
+	"github.com/mdhender/wraithh/models/nations"
+	"github.com/mdhender/wraithh/models/ships"
+	"github.com/mdhender/wraithh/models/systems"
+	"sort"
 )
 
+// SaveGame writes the state of the game to the directory it was loaded
+// from, replacing the files that LoadGame read.
 func (e *Engine) SaveGame(path string) error {
-	path = filepath.Join(path, "out")
 	game := GameJS{
 		Id:   e.Game.Id,
 		Name: e.Game.Name,
 		Turn: e.Game.Turn,
+		Seed: e.Game.Seed,
 	}
 	for _, player := range e.Players {
 		game.Players = append(game.Players, player.Id)
 	}
+	sort.Strings(game.Players)
 	if err := tojson(path, "game", game); err != nil {
 		return err
 	}
@@ -33,5 +40,73 @@ func (e *Engine) SaveGame(path string) error {
 		return err
 	}
 
+	var na []*nations.Nation
+	for _, n := range e.Nations {
+		na = append(na, n)
+	}
+	sort.Slice(na, func(i, j int) bool {
+		return na[i].Id < na[j].Id
+	})
+	if err := tojson(path, "nations", na); err != nil {
+		return err
+	}
+
+	var kn []*knowledge.Knowledge
+	for _, k := range e.Knowledge {
+		kn = append(kn, k)
+	}
+	sort.Slice(kn, func(i, j int) bool {
+		return kn[i].Nation < kn[j].Nation
+	})
+	if err := tojson(path, "knowledge", kn); err != nil {
+		return err
+	}
+
+	if err := tojson(path, "cluster", e.Cluster); err != nil {
+		return err
+	}
+
+	// the orbits and deposits are saved as part of the stars
+	var sy []*systems.System
+	for _, s := range e.Systems {
+		sy = append(sy, s)
+	}
+	sort.Slice(sy, func(i, j int) bool {
+		return sy[i].Id < sy[j].Id
+	})
+	if err := tojson(path, "systems", sy); err != nil {
+		return err
+	}
+
+	var st []*systems.Star
+	for _, s := range e.Stars {
+		st = append(st, s)
+	}
+	sort.Slice(st, func(i, j int) bool {
+		return st[i].Id < st[j].Id
+	})
+	if err := tojson(path, "stars", st); err != nil {
+		return err
+	}
+
+	if err := tojson(path, "ships", sortShips(e.Ships)); err != nil {
+		return err
+	}
+	if err := tojson(path, "colonies", sortShips(e.Colonies)); err != nil {
+		return err
+	}
+
 	return nil
 }
+
+// sortShips returns the ships sorted by unit number.
+func sortShips(m map[string]*ships.Ship) []*ships.Ship {
+	list := []*ships.Ship{}
+	for _, s := range m {
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return UnitIdLess(list[i].Id, list[j].Id)
+	})
+	return list
+}
@@ -9,11 +9,13 @@ import (
 	"github.com/mdhender/wraithh/models/coordinates"
 	"github.com/mdhender/wraithh/models/orbits"
 	"github.com/mdhender/wraithh/models/systems"
+	"github.com/mdhender/wraithh/templates"
 	"html/template"
 	"log"
 	"math"
 	"math/rand"
 	"os"
+	"time"
 )
 
 const (
@@ -26,20 +28,22 @@ const (
 // Generate creates a new cluster.
 func Generate(options ...Option) (*cluster.Cluster, []systems.System, []systems.Star, error) {
 	cfg := config{
-		initSystems:   128,
-		pgen:          points.ClusteredPoint,
-		clustered:     true,
-		radius:        15.0,
-		sphereSize:    sphereRatio,
-		templatesPath: "D:/wraith.dev/wraithh/templates/cluster.gohtml",
+		initSystems: 128,
+		pgen:        points.ClusteredPoint,
+		clustered:   true,
+		radius:      15.0,
+		sphereSize:  sphereRatio,
+		seed:        time.Now().UnixNano(),
 	}
 	for _, opt := range options {
 		if err := opt(&cfg); err != nil {
 			return nil, nil, nil, err
 		}
 	}
+	log.Printf("cluster: seed %d\n", cfg.seed)
+	r := rand.New(rand.NewSource(cfg.seed))
 
-	pp := points.NewPoints(cfg.initSystems*2, cfg.pgen)
+	pp := points.NewPoints(cfg.initSystems*2, r, cfg.pgen)
 	log.Println(pp.MinAvgMax())
 
 	cp := pp.CullByCompanions(6)
@@ -126,6 +130,7 @@ func Generate(options ...Option) (*cluster.Cluster, []systems.System, []systems.
 	}
 
 	const sysfix = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	dp := &deposits{}
 	c := &cluster.Cluster{Radius: cfg.radius}
 	var sy []systems.System
 	var st []systems.Star
@@ -159,7 +164,7 @@ func Generate(options ...Option) (*cluster.Cluster, []systems.System, []systems.
 					Id:       l.String(),
 					Location: l,
 				}
-				switch rand.Intn(5) {
+				switch r.Intn(5) {
 				case 0:
 					ob.Kind = orbits.Empty
 				case 1:
@@ -170,6 +175,7 @@ func Generate(options ...Option) (*cluster.Cluster, []systems.System, []systems.
 					ob.Kind = orbits.GasGiant
 				}
 				ob.Id = ob.Location.String()
+				generateOrbit(r, &ob, o, dp)
 				star.Orbits[o] = ob
 			}
 			st = append(st, star)
@@ -180,7 +186,14 @@ func Generate(options ...Option) (*cluster.Cluster, []systems.System, []systems.
 	}
 
 	if cfg.mapFile != "" {
-		ts, err := template.ParseFiles(cfg.templatesPath)
+		// use the embedded template unless another one was requested
+		var ts *template.Template
+		var err error
+		if cfg.templatesPath == "" {
+			ts, err = template.ParseFS(templates.FS, "cluster.gohtml")
+		} else {
+			ts, err = template.ParseFiles(cfg.templatesPath)
+		}
 		if err != nil {
 			return nil, nil, nil, err
 		}
@@ -0,0 +1,64 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package clusters
+
+import (
+	"bytes"
+	"github.com/mdhender/wraithh/models/cluster"
+	"github.com/mdhender/wraithh/models/systems"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// generated is everything that Generate creates.
+type generated struct {
+	cluster *cluster.Cluster
+	systems []systems.System
+	stars   []systems.Star
+	page    []byte // the HTML map
+}
+
+func generate(t *testing.T, seed int64) generated {
+	t.Helper()
+	name := filepath.Join(t.TempDir(), "cluster.html")
+	seedOpt, err := SetSeed(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mapOpt, err := CreateHtmlMap(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var g generated
+	if g.cluster, g.systems, g.stars, err = Generate(seedOpt, mapOpt); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if g.page, err = os.ReadFile(name); err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestGenerateIsReproducible(t *testing.T) {
+	first, second := generate(t, 42), generate(t, 42)
+	if !reflect.DeepEqual(first.cluster, second.cluster) {
+		t.Errorf("cluster differs for the same seed")
+	}
+	if !reflect.DeepEqual(first.systems, second.systems) {
+		t.Errorf("systems differ for the same seed")
+	}
+	if !reflect.DeepEqual(first.stars, second.stars) {
+		t.Errorf("stars differ for the same seed")
+	}
+	if !bytes.Equal(first.page, second.page) {
+		t.Errorf("map differs for the same seed")
+	}
+
+	// a different seed should give a different cluster
+	if other := generate(t, 43); reflect.DeepEqual(first.stars, other.stars) {
+		t.Errorf("stars are the same for different seeds")
+	}
+}
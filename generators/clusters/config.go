@@ -3,14 +3,18 @@
 
 package clusters
 
-import "github.com/mdhender/wraithh/generators/points"
+import (
+	"github.com/mdhender/wraithh/generators/points"
+	"math/rand"
+)
 
 type config struct {
-	initSystems   int                  // number of systems to seed cluster with
-	mapFile       string               // if set, create a map
-	pgen          func() *points.Point // points generator
+	initSystems   int                            // number of systems to seed cluster with
+	mapFile       string                         // if set, create a map
+	pgen          func(*rand.Rand) *points.Point // points generator
+	seed          int64                          // seed for the random number generator
 	clustered     bool
 	radius        float64
 	sphereSize    float64
-	templatesPath string // path to template files
+	templatesPath string // path to map template, empty to use the embedded one
 }
@@ -6,6 +6,7 @@ package clusters
 import (
 	"fmt"
 	"github.com/mdhender/wraithh/generators/points"
+	"math/rand"
 	"path/filepath"
 )
 
@@ -21,8 +22,20 @@ func CreateHtmlMap(name string) (func(*config) error, error) {
 	}, nil
 }
 
+// SetTemplate sets the path to the template used to create the map.
+// An empty path uses the template embedded in the binary.
+func SetTemplate(name string) (func(*config) error, error) {
+	return func(config *config) error {
+		config.templatesPath = name
+		if config.templatesPath != "" {
+			config.templatesPath = filepath.Clean(config.templatesPath)
+		}
+		return nil
+	}, nil
+}
+
 func SetKind(kind string) (func(*config) error, error) {
-	var pgen func() *points.Point
+	var pgen func(*rand.Rand) *points.Point
 	switch kind {
 	case "clustered":
 		pgen = points.ClusteredPoint
@@ -60,3 +73,12 @@ func SetSystems(n int) (func(*config) error, error) {
 		return nil
 	}, nil
 }
+
+// SetSeed sets the seed for the random number generator.
+// The same seed and options will always generate the same cluster.
+func SetSeed(seed int64) (func(*config) error, error) {
+	return func(config *config) error {
+		config.seed = seed
+		return nil
+	}, nil
+}
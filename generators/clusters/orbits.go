@@ -0,0 +1,105 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package clusters
+
+import (
+	"fmt"
+	"github.com/mdhender/wraithh/models/orbits"
+	"math/rand"
+)
+
+// deposits creates unique deposit ids for the cluster.
+type deposits struct {
+	next int
+}
+
+// id returns the next deposit id. It matches the DEPOSITID lexeme
+// that the order parser accepts.
+func (d *deposits) id() string {
+	d.next++
+	return fmt.Sprintf("DP-%d", d.next)
+}
+
+// generateOrbit sets the habitability and creates the deposits for an orbit.
+// Position is the orbit's distance from the star, 1...10.
+func generateOrbit(r *rand.Rand, ob *orbits.Orbit, position int, dp *deposits) {
+	ob.Habitability = habitability(r, ob.Kind, position)
+
+	// roll returns a quantity between lo and hi, inclusive
+	roll := func(lo, hi int) int {
+		return lo + r.Intn(hi-lo+1)
+	}
+	add := func(resource orbits.Resource, qty int) {
+		ob.Deposits = append(ob.Deposits, orbits.Deposit{
+			Id:           dp.id(),
+			Resource:     resource,
+			QtyInitial:   qty,
+			QtyRemaining: qty,
+		})
+	}
+
+	switch ob.Kind {
+	case orbits.AsteroidBelt:
+		// belts are rich in metals with the occasional vein of gold
+		for n := roll(3, 8); n > 0; n-- {
+			switch r.Intn(10) {
+			case 0:
+				add(orbits.Gold, roll(1_000, 10_000))
+			case 1, 2, 3, 4, 5:
+				add(orbits.Metallics, roll(50_000, 500_000))
+			default:
+				add(orbits.NonMetallics, roll(50_000, 500_000))
+			}
+		}
+	case orbits.GasGiant:
+		// gas giants hold huge reserves of fuel and little else
+		for n := roll(1, 4); n > 0; n-- {
+			switch r.Intn(4) {
+			case 0:
+				add(orbits.NonMetallics, roll(10_000, 100_000))
+			default:
+				add(orbits.Fuel, roll(1_000_000, 10_000_000))
+			}
+		}
+	case orbits.Terrestrial:
+		// terrestrial worlds have a little bit of everything
+		for n := roll(1, 6); n > 0; n-- {
+			switch r.Intn(20) {
+			case 0:
+				add(orbits.Gold, roll(500, 5_000))
+			case 1, 2, 3, 4, 5:
+				add(orbits.Fuel, roll(10_000, 100_000))
+			case 6, 7, 8, 9, 10, 11, 12:
+				add(orbits.Metallics, roll(10_000, 250_000))
+			default:
+				add(orbits.NonMetallics, roll(10_000, 250_000))
+			}
+		}
+	}
+}
+
+// habitability returns the habitability (0...25) of an orbit.
+// Only terrestrial worlds are habitable, and the best of them are
+// found in orbits 3 through 5.
+func habitability(r *rand.Rand, kind orbits.OrbitKind, position int) int {
+	if kind != orbits.Terrestrial {
+		return 0
+	}
+	var lo, hi int
+	switch position {
+	case 1:
+		lo, hi = 0, 3
+	case 2:
+		lo, hi = 3, 10
+	case 3, 4, 5:
+		lo, hi = 10, 25
+	case 6:
+		lo, hi = 3, 10
+	case 7:
+		lo, hi = 1, 5
+	default:
+		lo, hi = 0, 2
+	}
+	return lo + r.Intn(hi-lo+1)
+}
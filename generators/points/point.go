@@ -35,26 +35,26 @@ func (p *Point) Scale(n float64) *Point {
 	return &Point{X: p.X * n, Y: p.Y * n, Z: p.Z * n}
 }
 
-func ClusteredPoint() *Point {
-	var u = rand.Float64()
-	var v = rand.Float64()
+func ClusteredPoint(r *rand.Rand) *Point {
+	var u = r.Float64()
+	var v = r.Float64()
 	var theta = u * 2.0 * math.Pi
 	var phi = math.Acos(2.0*v - 1.0)
 	var sinTheta = math.Sin(theta)
 	var cosTheta = math.Cos(theta)
 	var sinPhi = math.Sin(phi)
 	var cosPhi = math.Cos(phi)
-	var r = rand.Float64()
+	var d = r.Float64()
 	return &Point{
-		X: r * sinPhi * cosTheta,
-		Y: r * sinPhi * sinTheta,
-		Z: r * cosPhi,
+		X: d * sinPhi * cosTheta,
+		Y: d * sinPhi * sinTheta,
+		Z: d * cosPhi,
 	}
 }
 
-func SpherePoint() *Point {
-	var u = rand.Float64()
-	var v = rand.Float64()
+func SpherePoint(r *rand.Rand) *Point {
+	var u = r.Float64()
+	var v = r.Float64()
 	var theta = u * 2.0 * math.Pi
 	var phi = math.Acos(2.0*v - 1.0)
 	var sinTheta = math.Sin(theta)
@@ -68,19 +68,19 @@ func SpherePoint() *Point {
 	}
 }
 
-func UniformPoint() *Point {
-	var u = rand.Float64()
-	var v = rand.Float64()
+func UniformPoint(r *rand.Rand) *Point {
+	var u = r.Float64()
+	var v = r.Float64()
 	var theta = u * 2.0 * math.Pi
 	var phi = math.Acos(2.0*v - 1.0)
 	var sinTheta = math.Sin(theta)
 	var cosTheta = math.Cos(theta)
 	var sinPhi = math.Sin(phi)
 	var cosPhi = math.Cos(phi)
-	var r = math.Cbrt(rand.Float64())
+	var d = math.Cbrt(r.Float64())
 	return &Point{
-		X: r * sinPhi * cosTheta,
-		Y: r * sinPhi * sinTheta,
-		Z: r * cosPhi,
+		X: d * sinPhi * cosTheta,
+		Y: d * sinPhi * sinTheta,
+		Z: d * cosPhi,
 	}
 }
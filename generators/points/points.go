@@ -5,6 +5,7 @@ package points
 
 import (
 	"math"
+	"math/rand"
 	"sort"
 )
 
@@ -12,10 +13,13 @@ type Points struct {
 	Points []*Point
 }
 
-func NewPoints(n int, pgen func() *Point) *Points {
+// NewPoints creates n points using the generator.
+// The random number source is passed to the generator so that the
+// same source always creates the same points.
+func NewPoints(n int, r *rand.Rand, pgen func(r *rand.Rand) *Point) *Points {
 	p := &Points{Points: make([]*Point, n, n)}
 	for i := range p.Points {
-		p.Points[i] = pgen()
+		p.Points[i] = pgen(r)
 	}
 	p.SetNeighbors(0)
 	return p
@@ -4,11 +4,7 @@
 package main
 
 import (
-	"fmt"
-	"github.com/mdhender/wraithh/adapters"
 	"github.com/mdhender/wraithh/cli"
-	"github.com/mdhender/wraithh/ec"
-	"github.com/mdhender/wraithh/parsers/orders"
 	"log"
 	"os"
 	"time"
@@ -34,39 +30,3 @@ func main() {
 
 	os.Exit(rv)
 }
-
-func run(path string, debug bool) error {
-	e, err := ec.LoadGame(path)
-	if err != nil {
-		return err
-	}
-
-	// load all the files
-	for _, name := range []string{"orders.txt"} {
-		input, err := os.ReadFile(name)
-		if err != nil {
-			return err
-		}
-		lexemes, err := orders.Scan(input)
-		if err != nil {
-			return err
-		}
-		ods := orders.Parse(lexemes)
-		if debug {
-			for _, od := range ods {
-				fmt.Println(od)
-			}
-		}
-		err = e.AddOrders(adapters.OrdersToEngineOrders(ods))
-		if err != nil {
-			log.Printf("%s: %v\n", name, err)
-		}
-	}
-
-	err = e.Process()
-	if err != nil {
-		return err
-	}
-
-	return e.SaveGame(path)
-}
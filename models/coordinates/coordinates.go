@@ -4,10 +4,11 @@
 package coordinates
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
+	"math"
 	"strconv"
+	"strings"
 )
 
 type Coordinates struct { // location being set up
@@ -31,20 +32,23 @@ func (c Coordinates) MarshalJSON() ([]byte, error) {
 
 // UnmarshalJSON implements the Unmarshaler interface.
 func (c *Coordinates) UnmarshalJSON(b []byte) error {
-	if !bytes.HasPrefix(b, []byte{'('}) || !bytes.HasSuffix(b, []byte{')'}) {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	if !strings.HasPrefix(s, "(") || !strings.HasSuffix(s, ")") {
 		return fmt.Errorf("invalid coordinates")
 	}
-	b = b[1 : len(b)-2]
-	f := bytes.Fields(b)
+	f := strings.Fields(s[1 : len(s)-1])
 	if len(f) < 3 || len(f) > 4 {
 		return fmt.Errorf("invalid coordinates")
 	}
-	x, y, z := string(f[0]), string(f[1]), string(f[2])
+	x, y, z := f[0], f[1], f[2]
 	var err error
-	if c.X, err = strconv.Atoi((x)); err != nil {
+	if c.X, err = strconv.Atoi(x); err != nil {
 		return fmt.Errorf("invalid coordinates")
 	}
-	if c.Y, err = strconv.Atoi((y)); err != nil {
+	if c.Y, err = strconv.Atoi(y); err != nil {
 		return fmt.Errorf("invalid coordinates")
 	}
 	c.System = z[len(z)-1:]
@@ -56,5 +60,32 @@ func (c *Coordinates) UnmarshalJSON(b []byte) error {
 	if c.Z, err = strconv.Atoi(z); err != nil {
 		return fmt.Errorf("invalid coordinates")
 	}
+	c.Orbit = 0
+	if len(f) == 4 {
+		if c.Orbit, err = strconv.Atoi(f[3]); err != nil {
+			return fmt.Errorf("invalid coordinates")
+		}
+	}
 	return nil
 }
+
+// DistanceTo returns the distance, in light years, between the two locations.
+// Stars and orbits within a system are treated as being at the same location.
+func (c Coordinates) DistanceTo(o Coordinates) float64 {
+	dx, dy, dz := float64(c.X-o.X), float64(c.Y-o.Y), float64(c.Z-o.Z)
+	return math.Sqrt(dx*dx + dy*dy + dz*dz)
+}
+
+// SystemLocation returns the location of the system containing these coordinates.
+func (c Coordinates) SystemLocation() Coordinates {
+	return Coordinates{X: c.X, Y: c.Y, Z: c.Z}
+}
+
+// StarLocation returns the location of the star containing these coordinates.
+// Coordinates without a star suffix are assumed to refer to the primary star.
+func (c Coordinates) StarLocation() Coordinates {
+	if c.System == "" {
+		return Coordinates{X: c.X, Y: c.Y, Z: c.Z, System: "A"}
+	}
+	return Coordinates{X: c.X, Y: c.Y, Z: c.Z, System: c.System}
+}
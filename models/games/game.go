@@ -8,4 +8,5 @@ type Game struct {
 	Id   string
 	Name string
 	Turn int
+	Seed int64 // seed for the random number generator used when processing turns
 }
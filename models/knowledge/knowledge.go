@@ -0,0 +1,178 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package knowledge records what a nation has learned about the cluster.
+package knowledge
+
+import (
+	"github.com/mdhender/wraithh/models/coordinates"
+	"github.com/mdhender/wraithh/models/orbits"
+	"github.com/mdhender/wraithh/models/ships"
+	"github.com/mdhender/wraithh/models/systems"
+)
+
+// Knowledge is everything a single nation has learned.
+// Every fact is stamped with the turn it was learned.
+type Knowledge struct {
+	Nation  string
+	Systems map[string]*System // systems seen, keyed by system id
+	Stars   map[string]*Star   // stars seen, keyed by star id
+	Orbits  map[string]*Orbit  // orbits seen or surveyed, keyed by orbit id
+	Units   map[string]*Unit   // ships and colonies seen or probed, keyed by unit id
+}
+
+// Detail is how much a nation has learned about something.
+type Detail int
+
+const (
+	// Seen is what a unit notices just by being nearby.
+	Seen Detail = iota + 1
+	// Surveyed is what a survey or probe reveals.
+	Surveyed
+)
+
+// System is a system that a nation has seen.
+type System struct {
+	Id       string
+	Location coordinates.Coordinates
+	Stars    []string // ids of the stars in the system
+	Turn     int      // turn the system was last seen
+}
+
+// Star is a star that a nation has seen.
+type Star struct {
+	Id       string
+	Location coordinates.Coordinates
+	Turn     int // turn the star was last seen
+}
+
+// Orbit is what a nation has learned about an orbit. Habitability and
+// deposits are only known once the orbit has been surveyed.
+type Orbit struct {
+	Id           string
+	Location     coordinates.Coordinates
+	Kind         orbits.OrbitKind
+	ControlledBy string
+	Habitability int
+	Deposits     []Deposit
+	Detail       Detail
+	Turn         int // turn the orbit was last seen or surveyed
+	SurveyTurn   int // turn the orbit was last surveyed, zero if never
+}
+
+// Deposit is what a survey revealed about a deposit.
+type Deposit struct {
+	Id           string
+	Resource     orbits.Resource
+	QtyRemaining int
+}
+
+// Unit is what a nation has learned about a ship or colony.
+// The mass is only known once the unit has been probed.
+type Unit struct {
+	Id       string
+	Owner    string
+	Kind     ships.Kind
+	Location coordinates.Coordinates
+	Mass     int
+	Detail   Detail
+	Turn     int // turn the unit was last seen or probed
+}
+
+// New returns an empty store for the nation.
+func New(nation string) *Knowledge {
+	k := &Knowledge{Nation: nation}
+	k.init()
+	return k
+}
+
+// init creates any maps that are missing, as they are from stores
+// saved before the maps were added.
+func (k *Knowledge) init() {
+	if k.Systems == nil {
+		k.Systems = make(map[string]*System)
+	}
+	if k.Stars == nil {
+		k.Stars = make(map[string]*Star)
+	}
+	if k.Orbits == nil {
+		k.Orbits = make(map[string]*Orbit)
+	}
+	if k.Units == nil {
+		k.Units = make(map[string]*Unit)
+	}
+}
+
+// SeeSystem records the system and its stars.
+func (k *Knowledge) SeeSystem(sys *systems.System, turn int) {
+	k.init()
+	k.Systems[sys.Id] = &System{
+		Id:       sys.Id,
+		Location: sys.Location,
+		Stars:    append([]string{}, sys.Stars...),
+		Turn:     turn,
+	}
+}
+
+// SeeStar records the star and the kind and controller of its orbits.
+// Orbits that have been surveyed keep their habitability and deposits.
+func (k *Knowledge) SeeStar(star *systems.Star, turn int) {
+	k.init()
+	k.Stars[star.Id] = &Star{Id: star.Id, Location: star.Location, Turn: turn}
+	for i := 1; i < len(star.Orbits); i++ {
+		orbit := &star.Orbits[i]
+		o, ok := k.Orbits[orbit.Id]
+		if !ok {
+			o = &Orbit{Id: orbit.Id, Location: orbit.Location, Detail: Seen}
+			k.Orbits[orbit.Id] = o
+		}
+		o.Kind, o.ControlledBy, o.Turn = orbit.Kind, orbit.ControlledBy, turn
+	}
+}
+
+// Survey records the orbit's kind, habitability and deposits,
+// replacing anything learned on an earlier turn.
+func (k *Knowledge) Survey(orbit *orbits.Orbit, turn int) {
+	k.init()
+	o := &Orbit{
+		Id:           orbit.Id,
+		Location:     orbit.Location,
+		Kind:         orbit.Kind,
+		ControlledBy: orbit.ControlledBy,
+		Habitability: orbit.Habitability,
+		Detail:       Surveyed,
+		Turn:         turn,
+		SurveyTurn:   turn,
+	}
+	for _, deposit := range orbit.Deposits {
+		o.Deposits = append(o.Deposits, Deposit{
+			Id:           deposit.Id,
+			Resource:     deposit.Resource,
+			QtyRemaining: deposit.QtyRemaining,
+		})
+	}
+	k.Orbits[o.Id] = o
+}
+
+// Sight records the ship or colony's owner, kind and location,
+// replacing anything learned on an earlier turn. A probe also reveals
+// the unit's mass.
+func (k *Knowledge) Sight(s *ships.Ship, detail Detail, turn int) {
+	k.init()
+	if old, ok := k.Units[s.Id]; ok && old.Turn == turn && old.Detail > detail {
+		// don't lose what was probed earlier in the turn
+		return
+	}
+	u := &Unit{
+		Id:       s.Id,
+		Owner:    s.Owner,
+		Kind:     s.Kind,
+		Location: s.Location,
+		Detail:   detail,
+		Turn:     turn,
+	}
+	if detail == Surveyed {
+		u.Mass = s.Mass()
+	}
+	k.Units[s.Id] = u
+}
@@ -0,0 +1,19 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package nations
+
+// Nation holds the state shared by every ship and colony a nation owns.
+type Nation struct {
+	Id        string
+	TechLevel int // highest tech level of units the nation can build, 1...10
+	Research  int // research points that have not been spent
+}
+
+// MaxTechLevel is the highest tech level a nation can reach.
+const MaxTechLevel = 10
+
+// ResearchCost returns the research points needed to advance to the tech level.
+func ResearchCost(tl int) int {
+	return 1_000 * tl * tl
+}
@@ -21,6 +21,9 @@ type Orbit struct {
 		Orbital []string // id of orbital colonies
 	}
 	Deposits []Deposit // deposits of resources
+	// Grants holds the nations that the controlling nation allows to
+	// use the orbit, keyed by the kind of right ("COLONIZE" or "TRADE").
+	Grants map[string][]string `json:",omitempty"`
 }
 
 type OrbitKind int
@@ -49,7 +52,7 @@ func (k OrbitKind) MarshalJSON() ([]byte, error) {
 
 // UnmarshalJSON implements the Unmarshaler interface.
 func (k *OrbitKind) UnmarshalJSON(b []byte) error {
-	if b == nil {
+	if b == nil || bytes.Compare(b, []byte(`null`)) == 0 {
 		*k = Empty
 		return nil
 	} else if bytes.Compare(b, []byte(`"asteroid-belt"`)) == 0 {
@@ -66,10 +69,66 @@ func (k *OrbitKind) UnmarshalJSON(b []byte) error {
 }
 
 type Deposit struct {
-	Id           string // unique identifier for deposit
+	Id           string // unique identifier for deposit, "DP-n"
 	Resource     Resource
 	ControlledBy string // id of nation controlling this deposit
 	QtyInitial   int
 	QtyRemaining int
 }
+
 type Resource int
+
+const (
+	NoResource Resource = iota
+	Fuel
+	Gold
+	Metallics
+	NonMetallics
+)
+
+// String returns the code the order parser uses for the resource.
+func (r Resource) String() string {
+	switch r {
+	case Fuel:
+		return "FUEL"
+	case Gold:
+		return "GOLD"
+	case Metallics:
+		return "MTL"
+	case NonMetallics:
+		return "NMTL"
+	}
+	return ""
+}
+
+// MarshalJSON implements the Marshaler interface.
+func (r Resource) MarshalJSON() ([]byte, error) {
+	switch r {
+	case NoResource:
+		return []byte(`null`), nil
+	case Fuel, Gold, Metallics, NonMetallics:
+		return []byte(`"` + r.String() + `"`), nil
+	}
+	return nil, fmt.Errorf("invalid resource")
+}
+
+// UnmarshalJSON implements the Unmarshaler interface.
+func (r *Resource) UnmarshalJSON(b []byte) error {
+	if b == nil || bytes.Compare(b, []byte(`null`)) == 0 {
+		*r = NoResource
+		return nil
+	} else if bytes.Compare(b, []byte(`"FUEL"`)) == 0 {
+		*r = Fuel
+		return nil
+	} else if bytes.Compare(b, []byte(`"GOLD"`)) == 0 {
+		*r = Gold
+		return nil
+	} else if bytes.Compare(b, []byte(`"MTL"`)) == 0 {
+		*r = Metallics
+		return nil
+	} else if bytes.Compare(b, []byte(`"NMTL"`)) == 0 {
+		*r = NonMetallics
+		return nil
+	}
+	return fmt.Errorf("invalid resource")
+}
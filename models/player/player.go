@@ -3,9 +3,63 @@
 
 package player
 
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
 type Player struct {
 	Id     string
 	Handle string
-	Secret string
+	Secret string // salted hash of the player's secret, never the plaintext
 	Nation string
 }
+
+// secretPrefix identifies a secret that has been salted and hashed.
+const secretPrefix = "sha256$"
+
+// HashSecret returns a salted hash of the secret that is safe to store.
+// Secrets are not case-sensitive and may not be blank.
+func HashSecret(secret string) (string, error) {
+	if strings.TrimSpace(secret) == "" {
+		return "", fmt.Errorf("secret is blank")
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	return hashSecret(salt, secret), nil
+}
+
+// IsHashed returns true if the secret has already been salted and hashed.
+func IsHashed(secret string) bool {
+	return strings.HasPrefix(secret, secretPrefix)
+}
+
+// VerifySecret returns true if the plaintext secret matches the player's hashed secret.
+// A blank secret never matches.
+func (p Player) VerifySecret(secret string) bool {
+	if strings.TrimSpace(secret) == "" || !IsHashed(p.Secret) {
+		return false
+	}
+	fields := strings.Split(strings.TrimPrefix(p.Secret, secretPrefix), "$")
+	if len(fields) != 2 {
+		return false
+	}
+	salt, err := hex.DecodeString(fields[0])
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(hashSecret(salt, secret)), []byte(p.Secret)) == 1
+}
+
+func hashSecret(salt []byte, secret string) string {
+	h := sha256.New()
+	h.Write(salt)
+	h.Write([]byte(strings.ToLower(secret)))
+	return fmt.Sprintf("%s%x$%x", secretPrefix, salt, h.Sum(nil))
+}
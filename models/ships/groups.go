@@ -0,0 +1,22 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package ships
+
+import "github.com/mdhender/wraithh/models/units"
+
+// FactoryGroup is a group of factories on a ship or colony
+// that manufacture a single product.
+type FactoryGroup struct {
+	Id          string             // "FG-n", unique within the ship or colony
+	Factories   map[units.Unit]int // number of factory units, by tech level
+	Manufacture units.Unit         // product being manufactured
+}
+
+// MineGroup is a group of mines on a ship or colony
+// that extract resources from a single deposit.
+type MineGroup struct {
+	Id        string             // "MG-n", unique within the ship or colony
+	DepositId string             // deposit being mined
+	Mines     map[units.Unit]int // number of mine units, by tech level
+}
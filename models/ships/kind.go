@@ -21,15 +21,15 @@ const (
 func (k Kind) MarshalJSON() ([]byte, error) {
 	switch k {
 	case Vessel:
-		return []byte(`null`), nil
+		return []byte(`"ship"`), nil
 	case EnclosedColony:
-		return []byte(`"asteroid-belt"`), nil
+		return []byte(`"enclosed"`), nil
 	case OpenColony:
-		return []byte(`"gas-giant"`), nil
+		return []byte(`"open"`), nil
 	case OrbitalColony:
-		return []byte(`"terrestrial"`), nil
+		return []byte(`"orbital"`), nil
 	}
-	return nil, fmt.Errorf("invalid orbit")
+	return nil, fmt.Errorf("invalid kind")
 }
 
 // UnmarshalJSON implements the Unmarshaler interface.
@@ -37,10 +37,10 @@ func (k *Kind) UnmarshalJSON(b []byte) error {
 	if bytes.Compare(b, []byte(`"ship"`)) == 0 {
 		*k = Vessel
 		return nil
-	} else if bytes.Compare(b, []byte(`"open"`)) == 0 {
+	} else if bytes.Compare(b, []byte(`"enclosed"`)) == 0 {
 		*k = EnclosedColony
 		return nil
-	} else if bytes.Compare(b, []byte(`"enclosed"`)) == 0 {
+	} else if bytes.Compare(b, []byte(`"open"`)) == 0 {
 		*k = OpenColony
 		return nil
 	} else if bytes.Compare(b, []byte(`"orbital"`)) == 0 {
@@ -0,0 +1,65 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package ships
+
+// StandardPay is the gold paid to each person every turn, by profession.
+// Professions without an entry in a ship's Pay are paid this rate.
+var StandardPay = map[string]float64{
+	"CIV":  0.0,
+	"CONS": 0.5,
+	"PRO":  0.375,
+	"SLD":  0.25,
+	"SPY":  0.625,
+	"UNSK": 0.125,
+}
+
+// PayRate returns the gold paid to each person in the profession every turn.
+func (s *Ship) PayRate(code string) float64 {
+	if rate, ok := s.Pay[code]; ok {
+		return rate
+	}
+	return StandardPay[code]
+}
+
+// RationPct returns the percentage of a full ration handed out every turn.
+func (s *Ship) RationPct() int {
+	if s.Rations == 0 {
+		return 100
+	}
+	return s.Rations
+}
+
+// TotalPopulation returns the number of people on board.
+func (s *Ship) TotalPopulation() int {
+	var total int
+	for _, qty := range s.Population {
+		total += qty
+	}
+	return total
+}
+
+// RebelProductionThreshold is the share of rebels at which they start
+// to cut the output of a ship or colony.
+const RebelProductionThreshold = 0.10
+
+// Productivity returns the factor applied to the output of the ship's
+// mines and factories. It ranges from 0.75 for a miserable crew to
+// 1.25 for a happy one. Once the rebels pass RebelProductionThreshold,
+// output is cut by their share of the population.
+func (s *Ship) Productivity() float64 {
+	factor := 1 + float64(s.Morale)/400
+	if share := s.RebelShare(); share >= RebelProductionThreshold {
+		factor *= 1 - share
+	}
+	return factor
+}
+
+// RebelShare returns the rebels' share of everyone on board, rebels included.
+func (s *Ship) RebelShare() float64 {
+	total := s.TotalPopulation() + s.Rebels
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Rebels) / float64(total)
+}
@@ -3,12 +3,198 @@
 
 package ships
 
-import "github.com/mdhender/wraithh/models/coordinates"
+import (
+	"fmt"
+	"github.com/mdhender/wraithh/models/coordinates"
+	"github.com/mdhender/wraithh/models/units"
+)
+
+// Independent is the owner of a colony that has thrown off its nation.
+const Independent = "0"
 
 // Ship is either a ship or a colony(?!!?).
+//
+// The hull is made of the structural units in Installed, and they
+// provide the cargo space for everything else on board: installed units,
+// stored units, factory and mine groups, population and resources.
 type Ship struct {
-	Id       string // unique identifier for ship or colony
-	Kind     Kind
-	Location coordinates.Coordinates
-	// attributes like hull, cargo, bridge, engines
+	Id            string // unique identifier for ship or colony
+	Owner         string // id of the nation that owns the ship or colony
+	Kind          Kind
+	Location      coordinates.Coordinates
+	Installed     map[units.Unit]int // operational units, including the hull, engines and sensors
+	Stored        map[units.Unit]int // units in storage
+	Population    map[string]int     // population, keyed by profession code (CIV, CONS, PRO, SLD, SPY, UNSK)
+	Resources     map[string]int     // resources on hand, keyed by code (FUEL, GOLD, MTL, NMTL)
+	FactoryGroups []*FactoryGroup
+	MineGroups    []*MineGroup
+	Pay           map[string]float64 // pay rate by profession, overriding StandardPay
+	Rations       int                // percentage of a full ration, zero means a full ration
+	Morale        int                // -100...100, zero is neutral
+	Rebels        int                // people in revolt against the owner, not counted in Population
+	Unrest        int                // 0...100, how close the population is to rebelling
+}
+
+// Capacity returns the cargo space provided by the hull.
+func (s *Ship) Capacity() int {
+	var total int
+	for unit, qty := range s.Installed {
+		total += qty * unit.Capacity()
+	}
+	return total
+}
+
+// Volume returns the cargo space used by everything inside the hull.
+// Installed structural units are the hull, so they take up no space,
+// but stored ones are cargo like anything else.
+func (s *Ship) Volume() int {
+	var total int
+	for unit, qty := range s.Installed {
+		if !unit.IsStructural() {
+			total += qty * unit.Volume()
+		}
+	}
+	s.cargo(func(unit units.Unit, qty int) {
+		total += qty * unit.Volume()
+	})
+	return total
+}
+
+// FreeCapacity returns the cargo space that is not being used.
+func (s *Ship) FreeCapacity() int {
+	return s.Capacity() - s.Volume()
+}
+
+// Mass returns the total mass of the ship, including the hull and cargo.
+func (s *Ship) Mass() int {
+	var total int
+	s.each(func(unit units.Unit, qty int) {
+		total += qty * unit.Mass()
+	})
+	return total
+}
+
+// each calls fn for every unit on board.
+func (s *Ship) each(fn func(unit units.Unit, qty int)) {
+	for unit, qty := range s.Installed {
+		fn(unit, qty)
+	}
+	s.cargo(fn)
+}
+
+// cargo calls fn for every unit on board that isn't installed.
+func (s *Ship) cargo(fn func(unit units.Unit, qty int)) {
+	for unit, qty := range s.Stored {
+		fn(unit, qty)
+	}
+	for _, fg := range s.FactoryGroups {
+		for unit, qty := range fg.Factories {
+			fn(unit, qty)
+		}
+	}
+	for _, mg := range s.MineGroups {
+		for unit, qty := range mg.Mines {
+			fn(unit, qty)
+		}
+	}
+	for code, qty := range s.Population {
+		fn(units.Unit{Name: code}, qty)
+	}
+	for code, qty := range s.Resources {
+		fn(units.Unit{Name: code}, qty)
+	}
+}
+
+// Quantity returns the number of units that could be taken from the cargo.
+// Population and resources are found by code; products must be in storage.
+func (s *Ship) Quantity(unit units.Unit) int {
+	switch {
+	case unit.IsPopulation():
+		return s.Population[unit.Name]
+	case unit.IsResource():
+		return s.Resources[unit.Name]
+	}
+	return s.Stored[unit]
+}
+
+// Put loads units into the cargo. It fails if there is not enough
+// free cargo space for them.
+func (s *Ship) Put(unit units.Unit, qty int) error {
+	if qty < 1 {
+		return fmt.Errorf("quantity must be positive")
+	} else if unit.Mass() == 0 {
+		return fmt.Errorf("%s can't be carried", unit)
+	} else if need, free := qty*unit.Volume(), s.FreeCapacity(); need > free {
+		return fmt.Errorf("unit %s needs %d cargo space for %s, has %d", s.Id, need, unit, free)
+	}
+	s.Add(unit, qty)
+	return nil
+}
+
+// Take removes units from the cargo. It fails if there are not enough of them.
+func (s *Ship) Take(unit units.Unit, qty int) error {
+	if qty < 1 {
+		return fmt.Errorf("quantity must be positive")
+	} else if have := s.Quantity(unit); have < qty {
+		return fmt.Errorf("unit %s has %d %s, needs %d", s.Id, have, unit, qty)
+	}
+	s.Add(unit, -qty)
+	return nil
+}
+
+// Add puts units into the cargo without checking the cargo space.
+// A negative quantity removes them.
+func (s *Ship) Add(unit units.Unit, qty int) {
+	switch {
+	case unit.IsPopulation():
+		s.AddPopulation(unit.Name, qty)
+	case unit.IsResource():
+		s.AddResource(unit.Name, qty)
+	default:
+		s.AddStored(unit, qty)
+	}
+}
+
+// AddInstalled adds operational units. A negative quantity removes them.
+func (s *Ship) AddInstalled(unit units.Unit, qty int) {
+	if s.Installed == nil {
+		s.Installed = make(map[units.Unit]int)
+	}
+	s.Installed[unit] += qty
+	if s.Installed[unit] == 0 {
+		delete(s.Installed, unit)
+	}
+}
+
+// AddPopulation adds people to a profession. A negative quantity removes them.
+func (s *Ship) AddPopulation(code string, qty int) {
+	if s.Population == nil {
+		s.Population = make(map[string]int)
+	}
+	s.Population[code] += qty
+	if s.Population[code] == 0 {
+		delete(s.Population, code)
+	}
+}
+
+// AddResource adds resources to the ship. A negative quantity removes them.
+func (s *Ship) AddResource(code string, qty int) {
+	if s.Resources == nil {
+		s.Resources = make(map[string]int)
+	}
+	s.Resources[code] += qty
+	if s.Resources[code] == 0 {
+		delete(s.Resources, code)
+	}
+}
+
+// AddStored adds units to storage. A negative quantity removes them.
+func (s *Ship) AddStored(unit units.Unit, qty int) {
+	if s.Stored == nil {
+		s.Stored = make(map[units.Unit]int)
+	}
+	s.Stored[unit] += qty
+	if s.Stored[unit] == 0 {
+		delete(s.Stored, unit)
+	}
 }
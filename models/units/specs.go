@@ -0,0 +1,97 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package units
+
+// spec holds the physical attributes of a kind of unit.
+type spec struct {
+	mass     int // mass of a single unit
+	volume   int // cargo space used by a single unit
+	capacity int // cargo space provided by a single installed unit
+}
+
+// specs holds the attributes for every unit, keyed by name.
+// Population and resources are listed per single person or unit.
+var specs = map[string]spec{
+	// population
+	"CIV":  {mass: 1, volume: 1},
+	"CONS": {mass: 1, volume: 1},
+	"PRO":  {mass: 1, volume: 1},
+	"SLD":  {mass: 1, volume: 1},
+	"SPY":  {mass: 1, volume: 1},
+	"UNSK": {mass: 1, volume: 1},
+	// resources
+	"FUEL": {mass: 1, volume: 1},
+	"GOLD": {mass: 1, volume: 1},
+	"MTL":  {mass: 1, volume: 1},
+	"NMTL": {mass: 1, volume: 1},
+	// products
+	"AMSL": {mass: 4, volume: 4},
+	"ASCR": {mass: 5, volume: 5},
+	"ASWP": {mass: 2, volume: 2},
+	"AUTO": {mass: 4, volume: 4},
+	"CNGD": {mass: 1, volume: 1},
+	"ESHD": {mass: 50, volume: 50},
+	"EWPN": {mass: 10, volume: 10},
+	"FACT": {mass: 12, volume: 12},
+	"FARM": {mass: 6, volume: 6},
+	"FOOD": {mass: 1, volume: 1},
+	"HDRV": {mass: 25, volume: 25},
+	"LS":   {mass: 8, volume: 8},
+	"MILR": {mass: 20, volume: 20},
+	"MILS": {mass: 1, volume: 1},
+	"MINE": {mass: 10, volume: 10},
+	"MSSL": {mass: 4, volume: 4},
+	"MSLN": {mass: 25, volume: 25},
+	"SNSR": {mass: 40, volume: 40},
+	"SDRV": {mass: 10, volume: 10},
+	"TRNS": {mass: 1, volume: 1},
+	// structural units are the hull and provide the cargo space
+	"SU":   {mass: 1, volume: 1, capacity: 10},
+	"LSU":  {mass: 1, volume: 1, capacity: 25},
+	"SLSU": {mass: 1, volume: 1, capacity: 50},
+}
+
+// IsPopulation returns true if the unit is a population profession.
+func (u Unit) IsPopulation() bool {
+	switch u.Name {
+	case "CIV", "CONS", "PRO", "SLD", "SPY", "UNSK":
+		return true
+	}
+	return false
+}
+
+// IsResource returns true if the unit is a raw resource.
+func (u Unit) IsResource() bool {
+	switch u.Name {
+	case "FUEL", "GOLD", "MTL", "NMTL":
+		return true
+	}
+	return false
+}
+
+// IsStructural returns true if the unit is part of a hull.
+func (u Unit) IsStructural() bool {
+	return specs[u.Name].capacity != 0
+}
+
+// Mass returns the mass of a single unit.
+// Units with no mass, like research, return zero.
+func (u Unit) Mass() int {
+	return specs[u.Name].mass
+}
+
+// Volume returns the cargo space used by a single unit.
+func (u Unit) Volume() int {
+	return specs[u.Name].volume
+}
+
+// Capacity returns the cargo space provided by a single installed unit.
+// Higher tech level structural units provide more space.
+func (u Unit) Capacity() int {
+	tl := u.TechLevel
+	if tl < 1 {
+		tl = 1
+	}
+	return specs[u.Name].capacity * tl
+}
@@ -3,16 +3,51 @@
 
 package units
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 type Unit struct {
 	Name      string // name
 	TechLevel int    // optional tech level
 }
 
+// IsProduct returns true if the unit is a manufactured product
+// rather than population, research, or a raw resource.
+func (u Unit) IsProduct() bool {
+	switch u.Name {
+	case "AMSL", "ASCR", "ASWP", "AUTO", "CNGD", "ESHD", "EWPN", "FACT", "FARM", "FOOD",
+		"HDRV", "LS", "LSU", "MILR", "MILS", "MINE", "MSSL", "MSLN", "SNSR", "SDRV",
+		"SU", "SLSU", "TRNS":
+		return true
+	}
+	return false
+}
+
 func (u Unit) String() string {
 	if u.TechLevel == 0 {
 		return u.Name
 	}
 	return fmt.Sprintf("%s-%d", u.Name, u.TechLevel)
 }
+
+// MarshalText implements the TextMarshaler interface.
+// It lets units be used as keys in JSON maps.
+func (u Unit) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText implements the TextUnmarshaler interface.
+func (u *Unit) UnmarshalText(b []byte) error {
+	s := string(b)
+	if n := strings.LastIndexByte(s, '-'); n != -1 {
+		if tl, err := strconv.Atoi(s[n+1:]); err == nil {
+			u.Name, u.TechLevel = s[:n], tl
+			return nil
+		}
+	}
+	u.Name, u.TechLevel = s, 0
+	return nil
+}
@@ -27,7 +27,7 @@ func Parse(lexemes []*Lexeme) []any {
 			order, lexemes = parseBuy(cmd, lexemes)
 		case "check-rebels":
 			order, lexemes = parseCheckRebels(cmd, lexemes)
-		case "claim":
+		case "claim", "control":
 			order, lexemes = parseClaim(cmd, lexemes)
 		case "convert-rebels":
 			order, lexemes = parseConvertRebels(cmd, lexemes)
@@ -0,0 +1,26 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package reports
+
+import (
+	"github.com/mdhender/wraithh/templates"
+	"html/template"
+	"io"
+)
+
+// WriteHTML renders the report using the HTML template at templatePath.
+// An empty path uses the template embedded in the binary.
+func (r *Report) WriteHTML(w io.Writer, templatePath string) error {
+	var ts *template.Template
+	var err error
+	if templatePath == "" {
+		ts, err = template.ParseFS(templates.FS, "report.gohtml")
+	} else {
+		ts, err = template.ParseFiles(templatePath)
+	}
+	if err != nil {
+		return err
+	}
+	return ts.Execute(w, r)
+}
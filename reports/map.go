@@ -0,0 +1,76 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package reports
+
+import (
+	"github.com/mdhender/wraithh/models/coordinates"
+	"github.com/mdhender/wraithh/models/knowledge"
+	"github.com/mdhender/wraithh/templates"
+	"html/template"
+	"io"
+	"sort"
+)
+
+// mapSize is the size of the sphere drawn for a system.
+const mapSize = 15.0 / 45.0
+
+// mapSystem is a system drawn on a nation's map.
+type mapSystem struct {
+	Id     string
+	Coords coordinates.Coordinates
+	Size   float64
+	// Black, Blue, Gray, Green, Magenta, Purple, Random, Red, Teal, White, Yellow
+	Color template.JS
+	Warps []coordinates.Point
+}
+
+// WriteMap draws the systems that the nation has seen using the
+// embedded cluster template. Systems the nation hasn't seen are left off.
+func WriteMap(w io.Writer, k *knowledge.Knowledge) error {
+	ts, err := template.ParseFS(templates.FS, "cluster.gohtml")
+	if err != nil {
+		return err
+	}
+	var set []*mapSystem
+	for _, sys := range k.Systems {
+		set = append(set, &mapSystem{
+			Id:     sys.Id,
+			Coords: sys.Location,
+			Size:   mapSize,
+			Color:  starColor(len(sys.Stars)),
+		})
+	}
+	sort.Slice(set, func(i, j int) bool {
+		return set[i].Id < set[j].Id
+	})
+	return ts.Execute(w, set)
+}
+
+// starColor returns the color used for a system with the number of
+// stars. It matches the colors used by the cluster generator.
+func starColor(n int) template.JS {
+	switch n {
+	case 1:
+		return "Black"
+	case 2:
+		return "Blue"
+	case 3:
+		return "Gray"
+	case 4:
+		return "Green"
+	case 5:
+		return "Magenta"
+	case 6:
+		return "Purple"
+	case 7:
+		return "Red"
+	case 8:
+		return "Teal"
+	case 9:
+		return "White"
+	case 10:
+		return "Yellow"
+	}
+	return "Random"
+}
@@ -0,0 +1,545 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package reports creates the turn reports sent to players.
+package reports
+
+import (
+	"fmt"
+	"github.com/mdhender/wraithh/ec"
+	"github.com/mdhender/wraithh/models/knowledge"
+	"github.com/mdhender/wraithh/models/orbits"
+	"github.com/mdhender/wraithh/models/ships"
+	"github.com/mdhender/wraithh/models/units"
+	"sort"
+	"strings"
+)
+
+// Report is a single player's report for a turn.
+type Report struct {
+	Game      string
+	Turn      int
+	Handle    string
+	Nation    string
+	TechLevel int
+	Research  int    // research points not yet spent
+	Error     string // set if the player's orders were rejected
+	Orders    []*Order
+	Ships     []*Unit
+	Colonies  []*Unit
+	Systems   []*System
+	News      []*Article
+	Market    []*Price
+	Battles   []*Battle
+	Raids     []*Raid
+	Missions  []*Mission
+	Revolts   []*Revolt
+	Permits   []*Permit
+	Surveys   []*Survey
+	Contacts  []*Contact
+}
+
+// Order is the outcome of a single order.
+type Order struct {
+	Line    int
+	Command string
+	Phase   string
+	Status  string // "ok" or the reason the order failed
+	Source  string // text of the order, set when it could not be parsed
+}
+
+// Unit is a ship or colony owned by the player's nation.
+type Unit struct {
+	Id       string
+	Kind     string
+	Location string
+	Fuel     int
+	Mass     int
+	Cargo    int // cargo space used
+	Capacity int // cargo space provided by the hull
+	Morale   int
+	Rations  int // percentage of a full ration
+	Unrest   int
+	Rebels   int
+	Items    []*Item
+}
+
+// Item is a line of inventory on a ship or colony.
+type Item struct {
+	Category string // installed, stored, population, resource, or a group id
+	Unit     string
+	Quantity int
+}
+
+// System is a system that the player's nation has seen.
+type System struct {
+	Id    string
+	Stars []*Star
+	Turn  int // turn the system was last seen
+}
+
+// Star is a star in a system, along with its orbits.
+type Star struct {
+	Id     string
+	Orbits []*Orbit
+}
+
+// Orbit is a single orbit around a star.
+type Orbit struct {
+	Id           string
+	Kind         string
+	ControlledBy string // id of the nation controlling the orbit
+}
+
+// Article is a news article published in a system the nation can see this turn.
+type Article struct {
+	Location  string
+	Article   string
+	Signature string
+}
+
+// Price is the outcome of the market for a single unit.
+type Price struct {
+	Unit    string
+	Bids    int
+	Asks    int
+	Traded  int
+	Price   float64
+	HighBid float64
+	LowAsk  float64
+}
+
+// Battle is a battle that one of the player's units fought in.
+type Battle struct {
+	Location    string
+	TargetId    string
+	Attackers   string
+	Defenders   string
+	Rounds      int
+	AttackerWon bool
+	Events      []string
+}
+
+// Raid is a raid that one of the player's units took part in,
+// as raider or target.
+type Raid struct {
+	Location      string
+	TargetId      string
+	Raiders       string
+	Succeeded     bool
+	AttackersLost int // soldiers killed on the raiding side
+	DefendersLost int // soldiers killed on the defending side
+	Taken         []*Item
+}
+
+// Mission is a spy mission run by the player's nation, or an enemy
+// mission that was caught by the nation's counter-spies.
+type Mission struct {
+	Kind      string
+	Nation    string // nation that ran the mission
+	UnitId    string
+	ColonyId  string
+	Spies     int
+	Succeeded bool
+	Caught    int
+	Result    string
+}
+
+// Revolt is a revolt on one of the player's colonies.
+type Revolt struct {
+	UnitId       string
+	Rebels       int
+	RebelsLost   int
+	SoldiersLost int
+	Independent  bool
+}
+
+// Permit is a right to use an orbit that the player's nation granted,
+// revoked, received or lost this turn.
+type Permit struct {
+	Location string
+	Kind     string
+	Granter  string
+	Grantee  string
+	Revoked  bool
+}
+
+// Survey is what the player's nation has learned about an orbit.
+type Survey struct {
+	Id           string
+	Kind         string
+	Habitability int
+	Deposits     []*Deposit
+	Turn         int // turn the orbit was surveyed
+}
+
+// Deposit is a deposit found by a survey.
+type Deposit struct {
+	Id       string
+	Resource string
+	Quantity int
+}
+
+// Contact is a ship or colony that the player's nation has seen or probed.
+type Contact struct {
+	Id       string
+	Owner    string
+	Kind     string
+	Location string
+	Mass     int // zero unless the unit was probed
+	Probed   bool
+	Turn     int // turn the unit was seen
+}
+
+// New creates the report for a single player from the state of the engine.
+// It should be called after the turn has been processed.
+//
+// Only the nation's own units come from the engine's state. Everything
+// about the rest of the cluster comes from the nation's knowledge, so the
+// report never shows what the nation hasn't seen.
+func New(e *ec.Engine, handle string) (*Report, error) {
+	r := &Report{
+		Game:   e.Game.Id,
+		Turn:   e.Processed,
+		Handle: handle,
+	}
+	var player string
+	for _, p := range e.Players {
+		if p.Handle == handle {
+			player, r.Nation = p.Id, p.Nation
+			break
+		}
+	}
+	if r.Nation == "" {
+		return nil, fmt.Errorf("%s: no such player", handle)
+	}
+	if n, ok := e.Nations[r.Nation]; ok {
+		r.TechLevel, r.Research = n.TechLevel, n.Research
+	}
+
+	// only orders that the secrets phase tied to the player are reported.
+	// If the player sent in several sets, the one that ran is reported.
+	// Anything else, like a set with a forged secret, is left to the referee.
+	var sent *ec.Orders
+	for _, po := range e.Orders {
+		if po.Player != player {
+			continue
+		} else if sent == nil || (po.Validated && !sent.Validated) {
+			sent = po
+		}
+	}
+	if sent != nil {
+		if !sent.Validated && sent.Error != nil {
+			r.Error = sent.Error.Error()
+		}
+		for _, result := range sent.Results {
+			o := &Order{
+				Line:    result.Line,
+				Command: command(result),
+				Phase:   result.Phase,
+				Status:  "ok",
+			}
+			if !result.Succeeded() {
+				o.Status = result.Error.Error()
+			}
+			if rejected, ok := result.Order.(*ec.Rejected); ok {
+				o.Source = rejected.Source
+			}
+			r.Orders = append(r.Orders, o)
+		}
+	}
+
+	for _, list := range []map[string]*ships.Ship{e.Ships, e.Colonies} {
+		for _, s := range list {
+			if s.Owner != r.Nation {
+				continue
+			}
+			u := &Unit{
+				Id:       s.Id,
+				Kind:     kind(s.Kind),
+				Location: s.Location.String(),
+				Fuel:     s.Resources["FUEL"],
+				Mass:     s.Mass(),
+				Cargo:    s.Volume(),
+				Capacity: s.Capacity(),
+				Morale:   s.Morale,
+				Rations:  s.RationPct(),
+				Unrest:   s.Unrest,
+				Rebels:   s.Rebels,
+				Items:    inventory(s),
+			}
+			if s.Kind == ships.Vessel {
+				r.Ships = append(r.Ships, u)
+			} else {
+				r.Colonies = append(r.Colonies, u)
+			}
+		}
+	}
+	sortUnits(r.Ships)
+	sortUnits(r.Colonies)
+
+	k := e.Knowledge[r.Nation]
+	if k == nil {
+		k = knowledge.New(r.Nation)
+	}
+
+	// known holds the known orbits of each star, in orbit order
+	known := make(map[string][]*knowledge.Orbit)
+	for _, o := range k.Orbits {
+		star := o.Location.StarLocation().String()
+		known[star] = append(known[star], o)
+	}
+	for _, list := range known {
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].Location.Orbit < list[j].Location.Orbit
+		})
+	}
+
+	for _, sys := range k.Systems {
+		rs := &System{Id: sys.Id, Turn: sys.Turn}
+		for _, starId := range sys.Stars {
+			if _, ok := k.Stars[starId]; !ok {
+				continue
+			}
+			rst := &Star{Id: starId}
+			for _, o := range known[starId] {
+				rst.Orbits = append(rst.Orbits, &Orbit{
+					Id:           o.Id,
+					Kind:         orbitKind(o.Kind),
+					ControlledBy: o.ControlledBy,
+				})
+			}
+			rs.Stars = append(rs.Stars, rst)
+		}
+		r.Systems = append(r.Systems, rs)
+	}
+	sort.Slice(r.Systems, func(i, j int) bool {
+		return r.Systems[i].Id < r.Systems[j].Id
+	})
+
+	for _, article := range e.News {
+		if sys, ok := k.Systems[article.Location.String()]; !ok || sys.Turn != e.Processed {
+			continue
+		}
+		r.News = append(r.News, &Article{
+			Location:  article.Location.String(),
+			Article:   article.Article,
+			Signature: article.Signature,
+		})
+	}
+
+	// every player sees the whole market
+	for _, p := range e.Market {
+		r.Market = append(r.Market, &Price{
+			Unit:    p.Unit.String(),
+			Bids:    p.Bids,
+			Asks:    p.Asks,
+			Traded:  p.Traded,
+			Price:   p.Price,
+			HighBid: p.HighBid,
+			LowAsk:  p.LowAsk,
+		})
+	}
+
+	for _, b := range e.Battles {
+		if !contains(b.Nations, r.Nation) {
+			continue
+		}
+		r.Battles = append(r.Battles, &Battle{
+			Location:    b.Location.String(),
+			TargetId:    b.TargetId,
+			Attackers:   strings.Join(b.Attackers, ", "),
+			Defenders:   strings.Join(b.Defenders, ", "),
+			Rounds:      b.Rounds,
+			AttackerWon: b.AttackerWon,
+			Events:      b.Events,
+		})
+		if len(b.Raiders) == 0 {
+			continue
+		}
+		raid := &Raid{
+			Location:      b.Location.String(),
+			TargetId:      b.TargetId,
+			Raiders:       strings.Join(b.Raiders, ", "),
+			Succeeded:     b.AttackerWon,
+			AttackersLost: b.AttackersLost,
+			DefendersLost: b.DefendersLost,
+		}
+		for _, p := range b.Plunder {
+			raid.Taken = append(raid.Taken, &Item{Category: p.RaiderId, Unit: p.Unit.String(), Quantity: p.Quantity})
+		}
+		r.Raids = append(r.Raids, raid)
+	}
+
+	for _, m := range e.Missions {
+		if m.Nation != r.Nation && !(m.Target == r.Nation && m.Caught != 0) {
+			continue
+		}
+		r.Missions = append(r.Missions, &Mission{
+			Kind:      m.Kind,
+			Nation:    m.Nation,
+			UnitId:    m.UnitId,
+			ColonyId:  m.ColonyId,
+			Spies:     m.Spies,
+			Succeeded: m.Succeeded,
+			Caught:    m.Caught,
+			Result:    m.Result,
+		})
+	}
+
+	for _, rv := range e.Revolts {
+		if rv.Owner != r.Nation {
+			continue
+		}
+		r.Revolts = append(r.Revolts, &Revolt{
+			UnitId:       rv.UnitId,
+			Rebels:       rv.Rebels,
+			RebelsLost:   rv.RebelsLost,
+			SoldiersLost: rv.SoldiersLost,
+			Independent:  rv.Independent,
+		})
+	}
+
+	for _, p := range e.Permits {
+		if p.Granter != r.Nation && p.Grantee != r.Nation {
+			continue
+		}
+		r.Permits = append(r.Permits, &Permit{
+			Location: p.Location.String(),
+			Kind:     strings.ToLower(p.Kind),
+			Granter:  p.Granter,
+			Grantee:  p.Grantee,
+			Revoked:  p.Revoked,
+		})
+	}
+
+	var stars []string
+	for star := range known {
+		stars = append(stars, star)
+	}
+	sort.Strings(stars)
+	for _, star := range stars {
+		for _, o := range known[star] {
+			if o.Detail != knowledge.Surveyed {
+				continue
+			}
+			rs := &Survey{
+				Id:           o.Id,
+				Kind:         orbitKind(o.Kind),
+				Habitability: o.Habitability,
+				Turn:         o.SurveyTurn,
+			}
+			for _, d := range o.Deposits {
+				rs.Deposits = append(rs.Deposits, &Deposit{Id: d.Id, Resource: d.Resource.String(), Quantity: d.QtyRemaining})
+			}
+			r.Surveys = append(r.Surveys, rs)
+		}
+	}
+
+	for _, u := range k.Units {
+		r.Contacts = append(r.Contacts, &Contact{
+			Id:       u.Id,
+			Owner:    u.Owner,
+			Kind:     kind(u.Kind),
+			Location: u.Location.String(),
+			Mass:     u.Mass,
+			Probed:   u.Detail == knowledge.Surveyed,
+			Turn:     u.Turn,
+		})
+	}
+	sort.Slice(r.Contacts, func(i, j int) bool {
+		return ec.UnitIdLess(r.Contacts[i].Id, r.Contacts[j].Id)
+	})
+
+	return r, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// command returns the name of the command for the order.
+func command(r *ec.Result) string {
+	name := fmt.Sprintf("%T", r.Order)
+	if n := strings.LastIndexByte(name, '.'); n != -1 {
+		name = name[n+1:]
+	}
+	return strings.ToLower(name)
+}
+
+func kind(k ships.Kind) string {
+	switch k {
+	case ships.Vessel:
+		return "ship"
+	case ships.EnclosedColony:
+		return "enclosed colony"
+	case ships.OpenColony:
+		return "open colony"
+	case ships.OrbitalColony:
+		return "orbital colony"
+	}
+	return "unknown"
+}
+
+func orbitKind(k orbits.OrbitKind) string {
+	switch k {
+	case orbits.Empty:
+		return "empty"
+	case orbits.AsteroidBelt:
+		return "asteroid belt"
+	case orbits.GasGiant:
+		return "gas giant"
+	case orbits.Terrestrial:
+		return "terrestrial"
+	}
+	return "unknown"
+}
+
+// inventory returns the items on board the ship or colony, sorted by
+// category and then by unit.
+func inventory(s *ships.Ship) []*Item {
+	var items []*Item
+	add := func(category string, unit units.Unit, qty int) {
+		items = append(items, &Item{Category: category, Unit: unit.String(), Quantity: qty})
+	}
+	for unit, qty := range s.Installed {
+		add("installed", unit, qty)
+	}
+	for unit, qty := range s.Stored {
+		add("stored", unit, qty)
+	}
+	for _, fg := range s.FactoryGroups {
+		for unit, qty := range fg.Factories {
+			add(fg.Id+" "+fg.Manufacture.String(), unit, qty)
+		}
+	}
+	for _, mg := range s.MineGroups {
+		for unit, qty := range mg.Mines {
+			add(mg.Id+" "+mg.DepositId, unit, qty)
+		}
+	}
+	for code, qty := range s.Population {
+		add("population", units.Unit{Name: code}, qty)
+	}
+	for code, qty := range s.Resources {
+		add("resource", units.Unit{Name: code}, qty)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Category != items[j].Category {
+			return items[i].Category < items[j].Category
+		}
+		return items[i].Unit < items[j].Unit
+	})
+	return items
+}
+
+func sortUnits(list []*Unit) {
+	sort.Slice(list, func(i, j int) bool {
+		return ec.UnitIdLess(list[i].Id, list[j].Id)
+	})
+}
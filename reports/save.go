@@ -0,0 +1,81 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package reports
+
+import (
+	"fmt"
+	"github.com/mdhender/wraithh/ec"
+	"github.com/mdhender/wraithh/models/knowledge"
+	"os"
+	"path/filepath"
+)
+
+// Save writes a text and an HTML report, along with a map of the systems
+// the player's nation has seen, for every player in the game to the
+// "reports" folder in the game's output directory.
+func Save(e *ec.Engine, path, templatePath string) error {
+	dir := filepath.Join(path, "out", "reports")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, p := range e.Players {
+		r, err := New(e, p.Handle)
+		if err != nil {
+			return err
+		}
+		name := filepath.Join(dir, fmt.Sprintf("%s-turn-%04d", p.Handle, e.Processed))
+		if err := save(name+".txt", func(w *os.File) error { return r.WriteText(w) }); err != nil {
+			return err
+		}
+		if err := save(name+".html", func(w *os.File) error { return r.WriteHTML(w, templatePath) }); err != nil {
+			return err
+		}
+		k, ok := e.Knowledge[p.Nation]
+		if !ok {
+			k = knowledge.New(p.Nation)
+		}
+		if err := save(name+"-map.html", func(w *os.File) error { return WriteMap(w, k) }); err != nil {
+			return err
+		}
+	}
+	return steal(e, dir)
+}
+
+// steal copies the target nation's report from the previous turn for
+// every spy mission that stole secrets. Every turn's reports are written
+// to the same folder, dir, so the stolen report is read from there. The
+// copy is named after the thief's report for this turn.
+func steal(e *ec.Engine, dir string) error {
+	handles := make(map[string]string)
+	for _, p := range e.Players {
+		handles[p.Nation] = p.Handle
+	}
+	for _, m := range e.Missions {
+		if m.Kind != "steal-secrets" || !m.Succeeded || handles[m.Nation] == "" || handles[m.Target] == "" {
+			continue
+		}
+		name := filepath.Join(dir, fmt.Sprintf("%s-turn-%04d-secrets-%s.txt", handles[m.Nation], e.Processed, m.Target))
+		stolen, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("%s-turn-%04d.txt", handles[m.Target], e.Processed-1)))
+		if os.IsNotExist(err) {
+			stolen = []byte(fmt.Sprintf("nation %s has no report for turn %d\n", m.Target, e.Processed-1))
+		} else if err != nil {
+			return err
+		}
+		if err := os.WriteFile(name, stolen, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func save(name string, write func(w *os.File) error) error {
+	w, err := os.OpenFile(name, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer func(fp *os.File) {
+		_ = fp.Close()
+	}(w)
+	return write(w)
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package reports
+
+import (
+	"github.com/mdhender/wraithh/ec"
+	"github.com/mdhender/wraithh/models/player"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStealCopiesLastTurnsReport(t *testing.T) {
+	dir := t.TempDir()
+	e := &ec.Engine{
+		Players: map[string]player.Player{
+			"1": {Id: "1", Handle: "alice", Nation: "1"},
+			"2": {Id: "2", Handle: "bob", Nation: "2"},
+			"3": {Id: "3", Handle: "carol", Nation: "3"},
+		},
+		Missions: []*ec.Mission{
+			{Kind: "steal-secrets", Nation: "1", Target: "2", Succeeded: true},
+			{Kind: "steal-secrets", Nation: "2", Target: "3", Succeeded: true},
+			{Kind: "steal-secrets", Nation: "3", Target: "1", Caught: 5},
+		},
+		Processed: 7,
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bob-turn-0006.txt"), []byte("bob's report\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := steal(e, dir); err != nil {
+		t.Fatalf("steal: %v", err)
+	}
+	for name, want := range map[string]string{
+		"alice-turn-0007-secrets-2.txt": "bob's report\n",
+		"bob-turn-0007-secrets-3.txt":   "nation 3 has no report for turn 6\n",
+	} {
+		if got, err := os.ReadFile(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s: %v", name, err)
+		} else if string(got) != want {
+			t.Errorf("%s: got %q, want %q", name, got, want)
+		}
+	}
+	// spies that were caught don't bring anything home
+	if _, err := os.Stat(filepath.Join(dir, "carol-turn-0007-secrets-1.txt")); !os.IsNotExist(err) {
+		t.Errorf("caught spies: report was copied")
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+package reports
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteText renders the report as plain text.
+func (r *Report) WriteText(w io.Writer) error {
+	sb := &strings.Builder{}
+
+	sb.WriteString(fmt.Sprintf("Game %s  Turn %d  Player %s  Nation %s\n", r.Game, r.Turn, r.Handle, r.Nation))
+	sb.WriteString(fmt.Sprintf("Tech Level %d  Research %d\n", r.TechLevel, r.Research))
+
+	sb.WriteString("\nOrders\n")
+	if r.Error != "" {
+		sb.WriteString(fmt.Sprintf("  orders rejected: %s\n", r.Error))
+	} else if len(r.Orders) == 0 {
+		sb.WriteString("  no orders received\n")
+	}
+	for _, o := range r.Orders {
+		sb.WriteString(fmt.Sprintf("  %4d  %-24s %s\n", o.Line, o.Command, o.Status))
+		if o.Source != "" {
+			sb.WriteString(fmt.Sprintf("        > %s\n", o.Source))
+		}
+	}
+
+	sb.WriteString("\nShips\n")
+	if len(r.Ships) == 0 {
+		sb.WriteString("  none\n")
+	}
+	for _, u := range r.Ships {
+		sb.WriteString(fmt.Sprintf("  %-8s %-16s %-20s fuel %d  mass %d  cargo %d/%d  morale %d  rations %d%%\n", u.Id, u.Kind, u.Location, u.Fuel, u.Mass, u.Cargo, u.Capacity, u.Morale, u.Rations))
+		writeItems(sb, u.Items)
+	}
+
+	sb.WriteString("\nColonies\n")
+	if len(r.Colonies) == 0 {
+		sb.WriteString("  none\n")
+	}
+	for _, u := range r.Colonies {
+		sb.WriteString(fmt.Sprintf("  %-8s %-16s %-20s cargo %d/%d  morale %d  rations %d%%  unrest %d  rebels %d\n", u.Id, u.Kind, u.Location, u.Cargo, u.Capacity, u.Morale, u.Rations, u.Unrest, u.Rebels))
+		writeItems(sb, u.Items)
+	}
+
+	sb.WriteString("\nSystems\n")
+	if len(r.Systems) == 0 {
+		sb.WriteString("  none\n")
+	}
+	for _, s := range r.Systems {
+		sb.WriteString(fmt.Sprintf("  %-20s turn %d\n", s.Id, s.Turn))
+		for _, star := range s.Stars {
+			sb.WriteString(fmt.Sprintf("    %s\n", star.Id))
+			for _, o := range star.Orbits {
+				if o.ControlledBy != "" {
+					sb.WriteString(fmt.Sprintf("      %-20s %-14s controlled by nation %s\n", o.Id, o.Kind, o.ControlledBy))
+					continue
+				}
+				sb.WriteString(fmt.Sprintf("      %-20s %s\n", o.Id, o.Kind))
+			}
+		}
+	}
+
+	sb.WriteString("\nSurveys\n")
+	if len(r.Surveys) == 0 {
+		sb.WriteString("  none\n")
+	}
+	for _, o := range r.Surveys {
+		sb.WriteString(fmt.Sprintf("  %-20s %-14s habitability %2d  turn %d\n", o.Id, o.Kind, o.Habitability, o.Turn))
+		for _, d := range o.Deposits {
+			sb.WriteString(fmt.Sprintf("      %-10s %-6s %12d\n", d.Id, d.Resource, d.Quantity))
+		}
+	}
+
+	sb.WriteString("\nContacts\n")
+	if len(r.Contacts) == 0 {
+		sb.WriteString("  none\n")
+	}
+	for _, c := range r.Contacts {
+		if c.Probed {
+			sb.WriteString(fmt.Sprintf("  %-8s %-16s %-20s nation %s  mass %d  turn %d\n", c.Id, c.Kind, c.Location, c.Owner, c.Mass, c.Turn))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("  %-8s %-16s %-20s nation %s  turn %d\n", c.Id, c.Kind, c.Location, c.Owner, c.Turn))
+	}
+
+	sb.WriteString("\nNews\n")
+	if len(r.News) == 0 {
+		sb.WriteString("  none\n")
+	}
+	for _, a := range r.News {
+		sb.WriteString(fmt.Sprintf("  %s\n    %s\n      -- %s\n", a.Location, a.Article, a.Signature))
+	}
+
+	sb.WriteString("\nMarket\n")
+	if len(r.Market) == 0 {
+		sb.WriteString("  no trading\n")
+	}
+	for _, p := range r.Market {
+		sb.WriteString(fmt.Sprintf("  %-12s bids %8d  asks %8d  traded %8d  price %10.2f  high bid %10.2f  low ask %10.2f\n", p.Unit, p.Bids, p.Asks, p.Traded, p.Price, p.HighBid, p.LowAsk))
+	}
+
+	sb.WriteString("\nBattles\n")
+	if len(r.Battles) == 0 {
+		sb.WriteString("  none\n")
+	}
+	for _, b := range r.Battles {
+		outcome := "defenders held"
+		if b.AttackerWon {
+			outcome = "attackers won"
+		}
+		sb.WriteString(fmt.Sprintf("  %s  target %s  attackers %s  defenders %s  %d rounds, %s\n", b.Location, b.TargetId, b.Attackers, b.Defenders, b.Rounds, outcome))
+		for _, event := range b.Events {
+			sb.WriteString(fmt.Sprintf("    %s\n", event))
+		}
+	}
+
+	sb.WriteString("\nRaids\n")
+	if len(r.Raids) == 0 {
+		sb.WriteString("  none\n")
+	}
+	for _, raid := range r.Raids {
+		outcome := "repelled"
+		if raid.Succeeded {
+			outcome = "succeeded"
+		}
+		sb.WriteString(fmt.Sprintf("  %s  target %s  raiders %s  %s\n", raid.Location, raid.TargetId, raid.Raiders, outcome))
+		sb.WriteString(fmt.Sprintf("    soldiers lost: raiders %d, defenders %d\n", raid.AttackersLost, raid.DefendersLost))
+		if len(raid.Taken) == 0 {
+			sb.WriteString("    nothing was taken\n")
+		}
+		for _, item := range raid.Taken {
+			sb.WriteString(fmt.Sprintf("    unit %s took %d %s\n", item.Category, item.Quantity, item.Unit))
+		}
+	}
+
+	sb.WriteString("\nSpy Missions\n")
+	if len(r.Missions) == 0 {
+		sb.WriteString("  none\n")
+	}
+	for _, m := range r.Missions {
+		if m.Nation != r.Nation {
+			sb.WriteString(fmt.Sprintf("  caught %d spies of nation %s on unit %s (%s)\n", m.Caught, m.Nation, m.ColonyId, m.Kind))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("  %-16s unit %-6s spies %6d  %s\n", m.Kind, m.UnitId, m.Spies, m.Result))
+	}
+
+	sb.WriteString("\nRevolts\n")
+	if len(r.Revolts) == 0 {
+		sb.WriteString("  none\n")
+	}
+	for _, rv := range r.Revolts {
+		if rv.Independent {
+			sb.WriteString(fmt.Sprintf("  colony %s: %d rebels took over and declared independence\n", rv.UnitId, rv.Rebels))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("  colony %s: %d rebels rose up, %d rebels and %d soldiers were killed\n", rv.UnitId, rv.Rebels, rv.RebelsLost, rv.SoldiersLost))
+	}
+
+	sb.WriteString("\nRights\n")
+	if len(r.Permits) == 0 {
+		sb.WriteString("  none\n")
+	}
+	for _, p := range r.Permits {
+		action := "granted"
+		if p.Revoked {
+			action = "revoked"
+		}
+		sb.WriteString(fmt.Sprintf("  %-20s nation %s %s the right to %s to nation %s\n", p.Location, p.Granter, action, p.Kind, p.Grantee))
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func writeItems(sb *strings.Builder, items []*Item) {
+	for _, item := range items {
+		sb.WriteString(fmt.Sprintf("      %-20s %-12s %12d\n", item.Category, item.Unit, item.Quantity))
+	}
+}
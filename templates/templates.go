@@ -0,0 +1,12 @@
+// Copyright (c) 2023 Michael D Henderson.
+// SPDX-License-Identifier: AGPL-3.0-or-later
+
+// Package templates embeds the default templates into the binary.
+package templates
+
+import "embed"
+
+// FS holds the default templates.
+//
+//go:embed *.gohtml
+var FS embed.FS